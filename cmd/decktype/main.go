@@ -0,0 +1,134 @@
+// Command decktype is a tool for the maintainers of the archetype rules.
+//
+// Usage:
+//
+//	decktype lint [-rules dir] [-strict]
+//	decktype resolve [-rules dir] <environment>
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+
+	"github.com/vsrecorder/decktype-api/internal/engine"
+	"github.com/vsrecorder/decktype-api/rules"
+	"gopkg.in/yaml.v3"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: decktype <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	fmt.Fprintln(os.Stderr, "  lint       check the rules of every environment for mistakes")
+	fmt.Fprintln(os.Stderr, "  resolve    print the rules of an environment with inherited rules applied")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "lint":
+		os.Exit(lint(os.Args[2:]))
+	case "resolve":
+		os.Exit(resolve(os.Args[2:]))
+	default:
+		usage()
+	}
+}
+
+// loadRules loads the rule sets from dir, or the embedded rules if dir is
+// empty.
+func loadRules(dir string) (map[string]*engine.RuleSet, error) {
+	var fsys fs.FS = rules.FS
+	if dir != "" {
+		fsys = os.DirFS(dir)
+	}
+
+	return engine.Load(fsys)
+}
+
+func sortedEnvironments(ruleSets map[string]*engine.RuleSet) []string {
+	envs := make([]string, 0, len(ruleSets))
+	for env := range ruleSets {
+		envs = append(envs, env)
+	}
+	slices.Sort(envs)
+
+	return envs
+}
+
+func lint(args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	dir := flags.String("rules", "", "directory holding the rule files (default: the embedded rules)")
+	strict := flags.Bool("strict", false, "report warnings and fail on them")
+	flags.Parse(args)
+
+	ruleSets, err := loadRules(*dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	errors, warnings := 0, 0
+	for _, env := range sortedEnvironments(ruleSets) {
+		for _, f := range engine.Lint(ruleSets[env]) {
+			if f.Severity == engine.SeverityWarning {
+				warnings++
+				if !*strict {
+					continue
+				}
+			} else {
+				errors++
+			}
+			fmt.Println(f)
+		}
+	}
+
+	if warnings > 0 && !*strict {
+		fmt.Fprintf(os.Stderr, "%d warnings hidden, run with -strict to see them\n", warnings)
+	}
+
+	if errors > 0 || *strict && warnings > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+func resolve(args []string) int {
+	flags := flag.NewFlagSet("resolve", flag.ExitOnError)
+	dir := flags.String("rules", "", "directory holding the rule files (default: the embedded rules)")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: decktype resolve [-rules dir] <environment>")
+		return 2
+	}
+
+	ruleSets, err := loadRules(*dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	rs, ok := ruleSets[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown environment %q\n", flags.Arg(0))
+		return 1
+	}
+
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(rs); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,94 @@
+// Package apierror writes the error responses of the API. Every error
+// response is a JSON object with a machine-readable code, which clients can
+// use to show a localized message, and an English message for humans:
+//
+//	{"code": "deck_not_found", "message": "failed to fetch deck ...: deck not found"}
+package apierror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vsrecorder/decktype-api/internal/decksource"
+)
+
+// Code identifies the kind of an error independently of its message.
+type Code string
+
+const (
+	// InvalidDeckCode: the deck code does not have the official format.
+	InvalidDeckCode Code = "invalid_deck_code"
+	// InvalidDeckList: the posted deck list is not a list of cards.
+	InvalidDeckList Code = "invalid_deck_list"
+	// DeckNotFound: the deckcards API does not know the deck code.
+	DeckNotFound Code = "deck_not_found"
+	// UnknownEnvironment: there are no rules for the environment.
+	UnknownEnvironment Code = "unknown_environment"
+	// InvalidDate: the date is not YYYY-MM-DD.
+	InvalidDate Code = "invalid_date"
+	// InvalidParameter: a query parameter is malformed.
+	InvalidParameter Code = "invalid_parameter"
+	// UpstreamTimeout: the deckcards API did not answer in time.
+	UpstreamTimeout Code = "upstream_timeout"
+	// UpstreamUnavailable: the deckcards API is failing and was not asked.
+	UpstreamUnavailable Code = "upstream_unavailable"
+	// UpstreamError: the deckcards API failed or answered with something
+	// other than a deck list.
+	UpstreamError Code = "upstream_error"
+	// Canceled: the client canceled the request.
+	Canceled Code = "canceled"
+)
+
+// StatusClientClosedRequest is the status of a request the client canceled
+// before the response was written; the client never sees it, but it shows
+// up in the access log.
+const StatusClientClosedRequest = 499
+
+// Error is the body of an error response.
+type Error struct {
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+}
+
+// Write writes an error response.
+func Write(ctx *gin.Context, status int, code Code, message string) {
+	ctx.JSON(status, &Error{Code: code, Message: message})
+}
+
+// WriteFetch writes the error response of a failed fetch of a deck list.
+func WriteFetch(ctx *gin.Context, err error) {
+	status, code := fetchStatus(err)
+	Write(ctx, status, code, err.Error())
+}
+
+// CheckDeckCode writes a 400 response and returns false if the deck code
+// does not have the official format, so it is never fetched.
+func CheckDeckCode(ctx *gin.Context, deckCode string) bool {
+	if !decksource.ValidDeckCode(deckCode) {
+		Write(ctx, http.StatusBadRequest, InvalidDeckCode, "Invalid deck code: "+deckCode)
+		return false
+	}
+
+	return true
+}
+
+func fetchStatus(err error) (int, Code) {
+	switch {
+	case decksource.IsKind(err, decksource.KindNotFound):
+		return http.StatusNotFound, DeckNotFound
+	case decksource.IsKind(err, decksource.KindTimeout):
+		return http.StatusGatewayTimeout, UpstreamTimeout
+	case decksource.IsKind(err, decksource.KindUnavailable):
+		return http.StatusServiceUnavailable, UpstreamUnavailable
+	case decksource.IsKind(err, decksource.KindCanceled):
+		return StatusClientClosedRequest, Canceled
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		// A request that gave up waiting for a fetch shared with another
+		// request gets the error of its own context.
+		return StatusClientClosedRequest, Canceled
+	default:
+		return http.StatusBadGateway, UpstreamError
+	}
+}
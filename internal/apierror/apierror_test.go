@@ -0,0 +1,76 @@
+package apierror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vsrecorder/decktype-api/internal/decksource"
+)
+
+func TestWriteFetch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for kind, want := range map[decksource.Kind]struct {
+		status int
+		code   Code
+	}{
+		decksource.KindNotFound:        {http.StatusNotFound, DeckNotFound},
+		decksource.KindTimeout:         {http.StatusGatewayTimeout, UpstreamTimeout},
+		decksource.KindUnavailable:     {http.StatusServiceUnavailable, UpstreamUnavailable},
+		decksource.KindUpstream:        {http.StatusBadGateway, UpstreamError},
+		decksource.KindInvalidResponse: {http.StatusBadGateway, UpstreamError},
+	} {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		WriteFetch(ctx, &decksource.Error{Kind: kind, DeckCode: "abc"})
+
+		var body Error
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != want.status || body.Code != want.code {
+			t.Errorf("%s: %d %s, want %d %s", kind, w.Code, body.Code, want.status, want.code)
+		}
+	}
+
+	for err, want := range map[error]int{
+		errors.New("boom"):       http.StatusBadGateway,
+		context.Canceled:         StatusClientClosedRequest,
+		context.DeadlineExceeded: StatusClientClosedRequest,
+		&decksource.Error{Kind: decksource.KindTimeout, Err: context.DeadlineExceeded}: http.StatusGatewayTimeout,
+	} {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		WriteFetch(ctx, err)
+		if w.Code != want {
+			t.Errorf("%v: %d, want %d", err, w.Code, want)
+		}
+	}
+}
+
+func TestCheckDeckCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for deckCode, want := range map[string]bool{
+		"kkkkF2-VHEc5W-k2fFkk": true,
+		"golden-000001-000000": true,
+		"kkkkF2-VHEc5W-k2fFk":  false,
+		"kkkkF2_VHEc5W_k2fFkk": false,
+		"../../etc/passwd":     false,
+		"":                     false,
+	} {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		if got := CheckDeckCode(ctx, deckCode); got != want {
+			t.Errorf("CheckDeckCode(%q) = %v, want %v", deckCode, got, want)
+		}
+		if !want && w.Code != http.StatusBadRequest {
+			t.Errorf("CheckDeckCode(%q) wrote %d, want %d", deckCode, w.Code, http.StatusBadRequest)
+		}
+	}
+}
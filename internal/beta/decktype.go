@@ -0,0 +1,13 @@
+package beta
+
+import (
+	"github.com/vsrecorder/decktype-api/internal/handlers"
+)
+
+// GetDeckType classifies the deck like handlers.GetDeckType and writes its
+// primary deck type in the beta shape.
+var GetDeckType = handlers.DeckTypeHandler(writeDeckType)
+
+// GetEnvironment classifies the deck like handlers.GetEnvironment and writes
+// its primary deck type in the beta shape.
+var GetEnvironment = handlers.EnvironmentHandler(writeDeckType)
@@ -1,20 +1,12 @@
 package beta
 
 import (
-	"encoding/json"
-	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/vsrecorder/decktype-api/internal/engine"
 )
 
-type Card struct {
-	Name      string `json:"name"`
-	DetailURL string `json:"detail_url"`
-	ImageURL  string `json:"image_url"`
-	Count     int    `json:"count"`
-}
-
 type AcespecCard struct {
 	Name     string `json:"name"`
 	ImageURL string `json:"image_url"`
@@ -33,152 +25,42 @@ type DeckType struct {
 	AcespecCard *AcespecCard `json:"acespec_card"`
 }
 
-func getAcespecCard(ctx *gin.Context, deckCode string) *AcespecCard {
-	resp, err := http.Get("https://vsrecorder.mobi/api/v1/deckcards/" + deckCode + "/acespec")
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, err.Error())
-		return nil
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		ctx.JSON(http.StatusInternalServerError, "Failed to fetch deck data: "+resp.Status)
-		return nil
-	}
-
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, err.Error())
-		return nil
-	}
-
-	var acespecCard *AcespecCard
-	if err := json.Unmarshal(body, acespecCard); err != nil {
-		ctx.JSON(http.StatusInternalServerError, err.Error())
-		return nil
+// writeDeckType writes the primary deck type of the result in the beta
+// shape, or 204 if no rule matched.
+func writeDeckType(ctx *gin.Context, result *engine.Result) {
+	if result.Primary == nil {
+		ctx.JSON(http.StatusNoContent, DeckType{})
+		return
 	}
 
-	return acespecCard
+	ctx.JSON(http.StatusOK, &DeckType{
+		MainTitle:   result.Primary.Archetype,
+		SubTitle:    result.Primary.SubTitle,
+		MainCards:   deckCards(result.Primary.MainCards),
+		SubCards:    deckCards(result.Primary.SubCards),
+		AcespecCard: acespecCard(result.AceSpec),
+	})
 }
 
-func analyzeJoltik(cardlist map[string]int, deck []*Card) *DeckType {
-	if cardlist["バチュル"] >= 2 && cardlist["サーフゴーex"] >= 3 {
-		var mainTitle string = "バチュル&サーフゴーex"
-		var subTitle string
-		var mainCards []*DeckCard
-		var subCards []*DeckCard
-
-		cards := []string{"バチュル", "サーフゴーex"}
-		for _, cardname := range cards {
-			for _, card := range deck {
-				if card.Name == cardname {
-					mainCards = append(
-						mainCards,
-						&DeckCard{
-							Name:     card.Name,
-							ImageURL: card.ImageURL,
-						},
-					)
-					break
-				}
-			}
-		}
-
-		decktype := &DeckType{
-			MainTitle: mainTitle,
-			MainCards: mainCards,
-			SubTitle:  subTitle,
-			SubCards:  subCards,
-		}
-
-		return decktype
-	} else if cardlist["バチュル"] >= 2 && cardlist["サーフゴーex"] == 0 {
-		var mainTitle string = "バチュルバレット"
-		var subTitle string
-		var mainCards []*DeckCard
-		var subCards []*DeckCard
-
-		cards := []string{"バチュル"}
-		for _, cardname := range cards {
-			for _, card := range deck {
-				if card.Name == cardname {
-					mainCards = append(
-						mainCards,
-						&DeckCard{
-							Name:     card.Name,
-							ImageURL: card.ImageURL,
-						},
-					)
-					break
-				}
-			}
-		}
-
-		decktype := &DeckType{
-			MainTitle: mainTitle,
-			MainCards: mainCards,
-			SubTitle:  subTitle,
-			SubCards:  subCards,
-		}
-
-		return decktype
+func deckCards(cards []*engine.MainCard) []*DeckCard {
+	var deckCards []*DeckCard
+	for _, card := range cards {
+		deckCards = append(deckCards, &DeckCard{
+			Name:     card.Name,
+			ImageURL: card.ImageURL,
+		})
 	}
 
-	return nil
+	return deckCards
 }
 
-func analyzeGholdengo_ex(cardlist map[string]int, deck []*Card) *DeckType {
-	if cardlist["サーフゴーex"] >= 3 && cardlist["バチュル"] == 0 {
-		var mainTitle string = "サーフゴーex"
-		var subTitle string
-		var mainCards []*DeckCard
-		var subCards []*DeckCard
-
-		cards := []string{"サーフゴーex"}
-		for _, cardname := range cards {
-			for _, card := range deck {
-				if card.Name == cardname {
-					mainCards = append(
-						mainCards,
-						&DeckCard{
-							Name:     card.Name,
-							ImageURL: card.ImageURL,
-						},
-					)
-					break
-				}
-			}
-		}
-
-		if cardlist["ルナトーン"] >= 2 && cardlist["ソルロック"] >= 2 {
-			cards = []string{"ルナトーン", "ソルロック"}
-			for _, cardname := range cards {
-				for _, card := range deck {
-					if card.Name == cardname {
-						subCards = append(
-							subCards,
-							&DeckCard{
-								Name:     card.Name,
-								ImageURL: card.ImageURL,
-							},
-						)
-						break
-					}
-				}
-			}
-			subTitle = "ルナトーン/ソルロック"
-		}
-
-		decktype := &DeckType{
-			MainTitle: mainTitle,
-			MainCards: mainCards,
-			SubTitle:  subTitle,
-			SubCards:  subCards,
-		}
-
-		return decktype
+func acespecCard(card *engine.MainCard) *AcespecCard {
+	if card == nil {
+		return nil
 	}
 
-	return nil
+	return &AcespecCard{
+		Name:     card.Name,
+		ImageURL: card.ImageURL,
+	}
 }
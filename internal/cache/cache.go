@@ -0,0 +1,160 @@
+// Package cache caches classification results per environment.
+package cache
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/vsrecorder/decktype-api/internal/engine"
+)
+
+// Config sets the capacity of the cache of an environment and how long a
+// result stays fresh. A TTL of zero keeps results until they are evicted.
+type Config struct {
+	Size int
+	TTL  time.Duration
+}
+
+// DefaultConfig is used for environments without a config of their own.
+var DefaultConfig = Config{Size: 2000}
+
+type entry struct {
+	result *engine.Result
+	added  time.Time
+}
+
+// Cache holds one LRU cache per environment, created on first use with the
+// config of that environment. Results are keyed by the version of the rule
+// set that produced them and the deck code, so a reload of the rules never
+// serves results of the previous rules. Expired results are kept until they
+// are evicted, so they can still be served when the deck cannot be fetched.
+type Cache struct {
+	mu      sync.Mutex
+	def     Config
+	configs map[string]Config
+	lrus    map[string]*lru.Cache[string, *entry]
+}
+
+// New returns a cache using the configs keyed by environment, and def for
+// every other environment.
+func New(def Config, configs map[string]Config) *Cache {
+	return &Cache{
+		def:     def,
+		configs: configs,
+		lrus:    make(map[string]*lru.Cache[string, *entry]),
+	}
+}
+
+// Get returns the fresh result of the deck under the rule set, if any.
+func (c *Cache) Get(ruleSet *engine.RuleSet, deckCode string) (*engine.Result, bool) {
+	e, ok := c.lru(ruleSet.Environment).Get(key(ruleSet, deckCode))
+	if !ok {
+		return nil, false
+	}
+
+	if ttl := c.config(ruleSet.Environment).TTL; ttl > 0 && time.Since(e.added) > ttl {
+		return nil, false
+	}
+
+	return e.result, true
+}
+
+// Stale returns the last result of the deck under the rule set, even if it
+// expired, as long as it has not been evicted.
+func (c *Cache) Stale(ruleSet *engine.RuleSet, deckCode string) (*engine.Result, bool) {
+	e, ok := c.lru(ruleSet.Environment).Peek(key(ruleSet, deckCode))
+	if !ok {
+		return nil, false
+	}
+
+	return e.result, true
+}
+
+// Add stores the result of the deck under the rule set.
+func (c *Cache) Add(ruleSet *engine.RuleSet, deckCode string, result *engine.Result) {
+	c.lru(ruleSet.Environment).Add(key(ruleSet, deckCode), &entry{
+		result: result,
+		added:  time.Now(),
+	})
+}
+
+func key(ruleSet *engine.RuleSet, deckCode string) string {
+	return ruleSet.Version + "/" + deckCode
+}
+
+func (c *Cache) config(env string) Config {
+	if config, ok := c.configs[env]; ok {
+		return config
+	}
+	return c.def
+}
+
+func (c *Cache) lru(env string) *lru.Cache[string, *entry] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, ok := c.lrus[env]
+	if !ok {
+		// New only fails for a size below one, which ConfigFromEnv rejects.
+		l, _ = lru.New[string, *entry](c.config(env).Size)
+		c.lrus[env] = l
+	}
+
+	return l
+}
+
+// ConfigFromEnv reads the default config from DECKTYPE_CACHE_SIZE and
+// DECKTYPE_CACHE_TTL, and the config of each environment from the same
+// variables suffixed with the environment in upper case, e.g.
+// DECKTYPE_CACHE_TTL_M4. An unset variable of an environment falls back to
+// the default config, an unset default to DefaultConfig. TTLs are Go
+// durations such as "24h".
+func ConfigFromEnv(envs []string) (Config, map[string]Config, error) {
+	def, err := configFromEnv("", DefaultConfig)
+	if err != nil {
+		return Config{}, nil, err
+	}
+
+	configs := make(map[string]Config)
+	for _, env := range envs {
+		config, err := configFromEnv("_"+strings.ToUpper(env), def)
+		if err != nil {
+			return Config{}, nil, err
+		}
+
+		if config != def {
+			configs[env] = config
+		}
+	}
+
+	return def, configs, nil
+}
+
+func configFromEnv(suffix string, def Config) (Config, error) {
+	config := def
+
+	name := "DECKTYPE_CACHE_SIZE" + suffix
+	if s := os.Getenv(name); s != "" {
+		size, err := strconv.Atoi(s)
+		if err != nil || size < 1 {
+			return Config{}, fmt.Errorf("%s: invalid size %q", name, s)
+		}
+		config.Size = size
+	}
+
+	name = "DECKTYPE_CACHE_TTL" + suffix
+	if s := os.Getenv(name); s != "" {
+		ttl, err := time.ParseDuration(s)
+		if err != nil || ttl < 0 {
+			return Config{}, fmt.Errorf("%s: invalid TTL %q", name, s)
+		}
+		config.TTL = ttl
+	}
+
+	return config, nil
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vsrecorder/decktype-api/internal/engine"
+)
+
+func TestNamespaces(t *testing.T) {
+	c := New(DefaultConfig, nil)
+
+	m3 := &engine.RuleSet{Environment: "m3", Version: "a"}
+	m4 := &engine.RuleSet{Environment: "m4", Version: "a"}
+	reloaded := &engine.RuleSet{Environment: "m4", Version: "b"}
+
+	result := &engine.Result{Environment: "m3"}
+	c.Add(m3, "deck", result)
+
+	if got, ok := c.Get(m3, "deck"); !ok || got != result {
+		t.Errorf("Get(m3) = %v, %v, want the added result", got, ok)
+	}
+
+	if _, ok := c.Get(m4, "deck"); ok {
+		t.Error("Get(m4) returned the result of m3")
+	}
+
+	c.Add(m4, "deck", &engine.Result{Environment: "m4"})
+	if _, ok := c.Get(reloaded, "deck"); ok {
+		t.Error("Get returned the result of a previous rule set version")
+	}
+}
+
+func TestTTL(t *testing.T) {
+	c := New(DefaultConfig, map[string]Config{"m4": {Size: 1, TTL: time.Millisecond}})
+
+	m3 := &engine.RuleSet{Environment: "m3", Version: "a"}
+	m4 := &engine.RuleSet{Environment: "m4", Version: "a"}
+
+	c.Add(m3, "deck", &engine.Result{})
+	c.Add(m4, "deck", &engine.Result{})
+	time.Sleep(2 * time.Millisecond)
+
+	if _, ok := c.Get(m4, "deck"); ok {
+		t.Error("Get(m4) returned an expired result")
+	}
+
+	if _, ok := c.Get(m3, "deck"); !ok {
+		t.Error("Get(m3) expired with the TTL of m4")
+	}
+}
@@ -0,0 +1,215 @@
+// Package decklist parses deck lists written as text, such as the lists
+// players paste from tournament sheets or export from PTCG Live:
+//
+//	4 ドラメシヤ SV6 089
+//	3 ドロンチ SV6 090
+//
+//	Pokémon: 12
+//	4 Dreepy TWM 128
+//	Trainer: 36
+//	4 Arven SVI 166
+package decklist
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/vsrecorder/decktype-api/internal/engine"
+)
+
+// aliasFile maps card names to the names the rules use, one
+// "name = 日本語名" pair per line. Empty lines and lines starting with "#"
+// are ignored.
+const aliasFile = "aliases.txt"
+
+// Aliases maps card names, such as the English names of PTCG Live exports,
+// to the Japanese names the rules use.
+type Aliases map[string]string
+
+// LoadAliases reads the aliases listed in fsys. The list is optional;
+// without it names are kept as written.
+//
+// Deck lists name ACE SPEC cards without the "(ACE SPEC)" suffix the
+// deckcards API gives them, and the rules with it, so the cards of the
+// ACE SPEC list in fsys resolve to their names with the suffix, whether
+// they are written in Japanese or through an alias.
+func LoadAliases(fsys fs.FS) (Aliases, error) {
+	aliases, err := readAliases(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	aceSpecs, err := engine.LoadAceSpecs(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, card := range aceSpecs {
+		for key, alias := range aliases {
+			if alias == card {
+				aliases[key] = card + engine.AceSpecSuffix
+			}
+		}
+		aliases[aliasKey(card)] = card + engine.AceSpecSuffix
+	}
+
+	return aliases, nil
+}
+
+func readAliases(fsys fs.FS) (Aliases, error) {
+	data, err := fs.ReadFile(fsys, aliasFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Aliases{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	aliases := Aliases{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, alias, ok := strings.Cut(line, "=")
+		name, alias = strings.TrimSpace(name), strings.TrimSpace(alias)
+		if !ok || name == "" || alias == "" {
+			return nil, fmt.Errorf("%s:%d: want \"name = 日本語名\"", aliasFile, n)
+		}
+
+		key := aliasKey(name)
+		if prev, ok := aliases[key]; ok && prev != alias {
+			return nil, fmt.Errorf("%s:%d: %q is already an alias of %q", aliasFile, n, name, prev)
+		}
+		aliases[key] = alias
+	}
+
+	return aliases, scanner.Err()
+}
+
+// Resolve returns the name the rules use for name.
+func (a Aliases) Resolve(name string) string {
+	if alias, ok := a[aliasKey(name)]; ok {
+		return alias
+	}
+
+	return name
+}
+
+// aliasKey folds the differences between the ways a name is written in
+// exports: case, accents and typographic apostrophes.
+func aliasKey(name string) string {
+	name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+	return strings.NewReplacer("é", "e", "’", "'").Replace(name)
+}
+
+// Entry is one card line of a deck list. Set and Number are empty if the
+// line did not give them.
+type Entry struct {
+	Line   int    `json:"line"`
+	Count  int    `json:"count"`
+	Name   string `json:"name"`
+	Set    string `json:"set,omitempty"`
+	Number string `json:"number,omitempty"`
+}
+
+// LineError is a line that could not be parsed.
+type LineError struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// List is a parsed deck list: its cards, in the order of the text, and the
+// lines that were neither cards nor headings.
+type List struct {
+	Entries []*Entry     `json:"cards"`
+	Errors  []*LineError `json:"unparsed_lines"`
+}
+
+var (
+	// "4 ドラメシヤ SV6 089", "4x Dreepy", "* 4 Dreepy TWM 128"
+	countFirst = regexp.MustCompile(`^(?:[*・-]\s*)?(\d+)\s*[x×]?\s+(.+)$`)
+	// "ドラメシヤ ×4", "ドラメシヤ 4枚"
+	countLast = regexp.MustCompile(`^(?:[*・-]\s*)?(.+?)\s*(?:[x×]\s*(\d+)|\s(\d+)\s*枚)$`)
+	// The set code and collector number at the end of a name, as in
+	// "Dreepy TWM 128", "ドラメシヤ SV6 089" or "Pikachu ex SSP 057/191".
+	setNumber = regexp.MustCompile(`^(.+?)\s+([A-Za-z][A-Za-z0-9-]*)\s+(\d+[A-Za-z]?(?:/\d+)?)$`)
+	// "Pokémon: 12", "Total Cards: 60", "ポケモン (12)", "グッズ"
+	heading = regexp.MustCompile(`(?i)^(?:pok[eé]mon|trainers?|energy|total cards|ポケモン|トレーナーズ|グッズ|ポケモンのどうぐ|サポート|スタジアム|エネルギー|基本エネルギー|特殊エネルギー|合計)\s*(?::\s*\d+|\(\d+\)|\d+\s*枚)?$`)
+)
+
+// fullWidth maps the full-width digits, letters and spaces of Japanese
+// input to their ASCII forms.
+var fullWidth = strings.NewReplacer(
+	"　", " ", "０", "0", "１", "1", "２", "2", "３", "3", "４", "4",
+	"５", "5", "６", "6", "７", "7", "８", "8", "９", "9",
+	"：", ":", "（", "(", "）", ")", "ｘ", "x", "／", "/",
+)
+
+// Parse parses a deck list, resolving card names through aliases. Empty
+// lines and headings such as "Pokémon: 12" are skipped; every other line
+// that is not a card is reported in the errors of the list.
+func Parse(text string, aliases Aliases) *List {
+	list := &List{Entries: []*Entry{}, Errors: []*LineError{}}
+
+	for i, raw := range strings.Split(text, "\n") {
+		line := strings.TrimSpace(fullWidth.Replace(raw))
+		if line == "" || heading.MatchString(line) {
+			continue
+		}
+
+		entry, ok := parseLine(line)
+		if !ok {
+			list.Errors = append(list.Errors, &LineError{Line: i + 1, Text: strings.TrimSpace(raw)})
+			continue
+		}
+
+		entry.Line = i + 1
+		entry.Name = aliases.Resolve(entry.Name)
+		list.Entries = append(list.Entries, entry)
+	}
+
+	return list
+}
+
+func parseLine(line string) (*Entry, bool) {
+	var count, rest string
+	if m := countFirst.FindStringSubmatch(line); m != nil {
+		count, rest = m[1], m[2]
+	} else if m := countLast.FindStringSubmatch(line); m != nil {
+		count, rest = m[2]+m[3], m[1]
+	} else {
+		return nil, false
+	}
+
+	n, err := strconv.Atoi(count)
+	if err != nil || n <= 0 {
+		return nil, false
+	}
+
+	entry := &Entry{Count: n, Name: rest}
+	if m := setNumber.FindStringSubmatch(rest); m != nil {
+		entry.Name, entry.Set, entry.Number = m[1], m[2], m[3]
+	}
+	entry.Name = strings.Join(strings.Fields(entry.Name), " ")
+
+	return entry, true
+}
+
+// Cards returns the cards of the list in the shape of the deckcards API
+// response, for classification. The cards have no image URLs.
+func (l *List) Cards() []*engine.Card {
+	cards := make([]*engine.Card, 0, len(l.Entries))
+	for _, entry := range l.Entries {
+		cards = append(cards, &engine.Card{Name: entry.Name, Count: entry.Count})
+	}
+
+	return cards
+}
@@ -0,0 +1,109 @@
+package decklist
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/vsrecorder/decktype-api/rules"
+)
+
+func TestParse(t *testing.T) {
+	aliases := Aliases{aliasKey("Dreepy"): "ドラメシヤ", aliasKey("Pokémon Catcher"): "ポケモンキャッチャー"}
+
+	list := Parse(`ポケモン (2)
+4 ドラメシヤ SV6 089
+３　ドロンチ　SV6　090
+オーガポン みどりのめんex ×2
+ネストボール 4枚
+
+Pokémon: 1
+* 4 Dreepy TWM 128
+Trainer: 1
+2x Pokemon Catcher SVI 187
+1 Pikachu ex SSP 057/191
+Total Cards: 60
+ドラパルトex
+0 ドラメシヤ
+`, aliases)
+
+	want := []*Entry{
+		{Line: 2, Count: 4, Name: "ドラメシヤ", Set: "SV6", Number: "089"},
+		{Line: 3, Count: 3, Name: "ドロンチ", Set: "SV6", Number: "090"},
+		{Line: 4, Count: 2, Name: "オーガポン みどりのめんex"},
+		{Line: 5, Count: 4, Name: "ネストボール"},
+		{Line: 8, Count: 4, Name: "ドラメシヤ", Set: "TWM", Number: "128"},
+		{Line: 10, Count: 2, Name: "ポケモンキャッチャー", Set: "SVI", Number: "187"},
+		{Line: 11, Count: 1, Name: "Pikachu ex", Set: "SSP", Number: "057/191"},
+	}
+	if !reflect.DeepEqual(list.Entries, want) {
+		for _, e := range list.Entries {
+			t.Logf("%+v", e)
+		}
+		t.Error("unexpected entries")
+	}
+
+	wantErrors := []*LineError{{Line: 13, Text: "ドラパルトex"}, {Line: 14, Text: "0 ドラメシヤ"}}
+	if !reflect.DeepEqual(list.Errors, wantErrors) {
+		for _, e := range list.Errors {
+			t.Logf("%+v", e)
+		}
+		t.Error("unexpected errors")
+	}
+}
+
+func TestLoadAliases(t *testing.T) {
+	aliases, err := LoadAliases(fstest.MapFS{
+		aliasFile: {Data: []byte("# comment\n\nBoss’s Orders = ボスの指令\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := aliases.Resolve("boss's  orders"); got != "ボスの指令" {
+		t.Errorf("Resolve = %q, want ボスの指令", got)
+	}
+	if got := aliases.Resolve("ナンジャモ"); got != "ナンジャモ" {
+		t.Errorf("Resolve = %q, want the name kept", got)
+	}
+
+	aliases, err = LoadAliases(fstest.MapFS{
+		aliasFile:     {Data: []byte("Neutral Center = ニュートラルセンター\n")},
+		"acespec.txt": {Data: []byte("ニュートラルセンター\nマスターボール\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"Neutral Center":       "ニュートラルセンター(ACE SPEC)",
+		"ニュートラルセンター":           "ニュートラルセンター(ACE SPEC)",
+		"ニュートラルセンター(ACE SPEC)": "ニュートラルセンター(ACE SPEC)",
+		"マスターボール":              "マスターボール(ACE SPEC)",
+		"ナンジャモ":                "ナンジャモ",
+	} {
+		if got := aliases.Resolve(name); got != want {
+			t.Errorf("Resolve(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	for _, data := range []string{"Boss's Orders\n", "Iono = ナンジャモ\niono = ペパー\n"} {
+		if _, err := LoadAliases(fstest.MapFS{aliasFile: {Data: []byte(data)}}); err == nil {
+			t.Errorf("LoadAliases(%q) succeeded", data)
+		}
+	}
+}
+
+// TestRulesAliases checks that the embedded alias table loads.
+func TestRulesAliases(t *testing.T) {
+	aliases, err := LoadAliases(rules.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"Dragapult ex":   "ドラパルトex",
+		"Neutral Center": "ニュートラルセンター(ACE SPEC)",
+	} {
+		if got := aliases.Resolve(name); got != want {
+			t.Errorf("Resolve(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
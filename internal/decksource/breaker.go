@@ -0,0 +1,100 @@
+package decksource
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/vsrecorder/decktype-api/internal/engine"
+)
+
+// Breaker is a circuit breaker around a deck source. After a number of
+// consecutive failures of the upstream it opens and fails every fetch with
+// KindUnavailable, without asking the upstream, until the cooldown has
+// passed. Then it lets a single fetch through: if it succeeds the breaker
+// closes again, otherwise it stays open for another cooldown.
+//
+// Only timeouts and upstream errors count as failures; an unknown deck code
+// says nothing about the health of the upstream, and a canceled fetch
+// counts neither way. A breaker with failures below one never opens.
+type Breaker struct {
+	source   Source
+	failures int
+	cooldown time.Duration
+
+	mu       sync.Mutex
+	failed   int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewBreaker returns a breaker around source that opens after failures
+// consecutive failures and stays open for cooldown.
+func NewBreaker(source Source, failures int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		source:   source,
+		failures: failures,
+		cooldown: cooldown,
+	}
+}
+
+func (b *Breaker) Fetch(ctx context.Context, deckCode string) ([]*engine.Card, error) {
+	probe, ok := b.allow()
+	if !ok {
+		return nil, &Error{Kind: KindUnavailable, DeckCode: deckCode}
+	}
+
+	deck, err := b.source.Fetch(ctx, deckCode)
+	b.record(probe, err)
+
+	return deck, err
+}
+
+// Open reports whether the breaker is open.
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.failures > 0 && b.failed >= b.failures
+}
+
+// allow reports whether a fetch may go to the upstream, and whether it is
+// the probe of an open breaker.
+func (b *Breaker) allow() (probe bool, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures <= 0 || b.failed < b.failures {
+		return false, true
+	}
+
+	if b.probing || time.Since(b.openedAt) < b.cooldown {
+		return false, false
+	}
+
+	b.probing = true
+	return true, true
+}
+
+func (b *Breaker) record(probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+
+	if IsKind(err, KindCanceled) {
+		return
+	}
+
+	if !IsKind(err, KindUpstream) && !IsKind(err, KindTimeout) {
+		b.failed = 0
+		return
+	}
+
+	b.failed++
+	if b.failed >= b.failures {
+		b.openedAt = time.Now()
+	}
+}
@@ -0,0 +1,77 @@
+package decksource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vsrecorder/decktype-api/internal/engine"
+)
+
+type fakeSource struct {
+	calls int
+	err   error
+}
+
+func (s *fakeSource) Fetch(ctx context.Context, deckCode string) ([]*engine.Card, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []*engine.Card{}, nil
+}
+
+func TestBreaker(t *testing.T) {
+	upstream := &fakeSource{err: &Error{Kind: KindUpstream}}
+	b := NewBreaker(upstream, 2, 20*time.Millisecond)
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := b.Fetch(ctx, "abc"); !IsKind(err, KindUpstream) {
+			t.Fatalf("Fetch = %v, want kind %s", err, KindUpstream)
+		}
+	}
+	if !b.Open() {
+		t.Fatal("breaker closed after 2 failures")
+	}
+
+	if _, err := b.Fetch(ctx, "abc"); !IsKind(err, KindUnavailable) {
+		t.Errorf("Fetch = %v, want kind %s", err, KindUnavailable)
+	}
+	if upstream.calls != 2 {
+		t.Errorf("upstream called %d times while open, want 2", upstream.calls)
+	}
+
+	// The probe after the cooldown fails and opens the breaker again.
+	time.Sleep(30 * time.Millisecond)
+	if _, err := b.Fetch(ctx, "abc"); !IsKind(err, KindUpstream) {
+		t.Errorf("probe = %v, want kind %s", err, KindUpstream)
+	}
+	if _, err := b.Fetch(ctx, "abc"); !IsKind(err, KindUnavailable) {
+		t.Errorf("Fetch after failed probe = %v, want kind %s", err, KindUnavailable)
+	}
+
+	// The probe after the next cooldown succeeds and closes it.
+	upstream.err = nil
+	time.Sleep(30 * time.Millisecond)
+	if _, err := b.Fetch(ctx, "abc"); err != nil {
+		t.Errorf("probe = %v", err)
+	}
+	if b.Open() {
+		t.Error("breaker open after successful probe")
+	}
+}
+
+func TestBreakerNotFound(t *testing.T) {
+	upstream := &fakeSource{err: &Error{Kind: KindNotFound}}
+	b := NewBreaker(upstream, 1, time.Minute)
+
+	for range 3 {
+		if _, err := b.Fetch(context.Background(), "abc"); !IsKind(err, KindNotFound) {
+			t.Fatalf("Fetch = %v, want kind %s", err, KindNotFound)
+		}
+	}
+	if b.Open() {
+		t.Error("breaker opened on unknown deck codes")
+	}
+}
@@ -0,0 +1,335 @@
+// Package decksource fetches deck lists, from the deckcards API or, for
+// development and tests, from local fixtures.
+package decksource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vsrecorder/decktype-api/internal/engine"
+)
+
+// Source fetches the deck list of a deck code. Every error it returns is
+// an *Error.
+type Source interface {
+	Fetch(ctx context.Context, deckCode string) ([]*engine.Card, error)
+}
+
+// deckCodePattern is the format of the deck codes issued by the official deck
+// builder: three groups of six letters or digits, such as
+// "kkkkF2-VHEc5W-k2fFkk".
+var deckCodePattern = regexp.MustCompile(`^[0-9A-Za-z]{6}-[0-9A-Za-z]{6}-[0-9A-Za-z]{6}$`)
+
+// ValidDeckCode reports whether the deck code has the format of the official
+// deck builder. A valid deck code may still be unknown.
+func ValidDeckCode(deckCode string) bool {
+	return deckCodePattern.MatchString(deckCode)
+}
+
+// Kind classifies why a deck could not be fetched.
+type Kind int
+
+const (
+	// KindNotFound: the deckcards API does not know the deck code.
+	KindNotFound Kind = iota + 1
+	// KindTimeout: the deckcards API did not answer in time.
+	KindTimeout
+	// KindCanceled: the request that needed the deck was canceled.
+	KindCanceled
+	// KindUpstream: the deckcards API failed or could not be reached.
+	KindUpstream
+	// KindTooLarge: the response exceeded the size limit.
+	KindTooLarge
+	// KindInvalidResponse: the response was not a deck list.
+	KindInvalidResponse
+	// KindUnavailable: the deckcards API was not asked because it is
+	// failing; see Breaker.
+	KindUnavailable
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNotFound:
+		return "deck not found"
+	case KindTimeout:
+		return "timed out"
+	case KindCanceled:
+		return "canceled"
+	case KindUpstream:
+		return "upstream error"
+	case KindTooLarge:
+		return "response too large"
+	case KindUnavailable:
+		return "upstream unavailable"
+	default:
+		return "invalid response"
+	}
+}
+
+// Error is the error of every failed fetch.
+type Error struct {
+	Kind     Kind
+	DeckCode string
+
+	// Status is the HTTP status of the last response, if there was one.
+	Status int
+
+	Err error
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("failed to fetch deck %s: %s", e.DeckCode, e.Kind)
+	if e.Status != 0 {
+		msg += fmt.Sprintf(" (%d %s)", e.Status, http.StatusText(e.Status))
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsKind reports whether err is an *Error of the given kind.
+func IsKind(err error, kind Kind) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Kind == kind
+}
+
+// Config tunes the requests to the deckcards API.
+type Config struct {
+	// BaseURL is the URL deck codes are appended to.
+	BaseURL string
+
+	// Timeout bounds each attempt.
+	Timeout time.Duration
+
+	// Retries is how many times a 5xx response is retried.
+	Retries int
+
+	// Backoff is the wait before the first retry. It doubles with every
+	// further retry.
+	Backoff time.Duration
+
+	// MaxBytes limits the size of a response body.
+	MaxBytes int64
+}
+
+// DefaultConfig is used for settings that are not configured.
+var DefaultConfig = Config{
+	BaseURL:  "https://vsrecorder.mobi/api/v1/deckcards/",
+	Timeout:  5 * time.Second,
+	Retries:  2,
+	Backoff:  200 * time.Millisecond,
+	MaxBytes: 1 << 20,
+}
+
+// HTTP fetches deck lists from the deckcards API.
+type HTTP struct {
+	client *http.Client
+	config Config
+}
+
+// NewHTTP returns a deck source using config.
+func NewHTTP(config Config) *HTTP {
+	return &HTTP{
+		client: &http.Client{},
+		config: config,
+	}
+}
+
+// Fetch returns the deck list of deckCode. It gives up when ctx is done.
+func (s *HTTP) Fetch(ctx context.Context, deckCode string) ([]*engine.Card, error) {
+	backoff := s.config.Backoff
+
+	for attempt := 0; ; attempt++ {
+		deck, err := s.fetch(ctx, deckCode)
+		if attempt == s.config.Retries || !retryable(err) {
+			return deck, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, &Error{Kind: KindCanceled, DeckCode: deckCode, Err: ctx.Err()}
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// retryable reports whether err is a 5xx response.
+func retryable(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Kind == KindUpstream && e.Status >= 500
+}
+
+func (s *HTTP) fetch(ctx context.Context, deckCode string) ([]*engine.Card, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	fail := func(kind Kind, status int, err error) ([]*engine.Card, error) {
+		if ctx.Err() != nil && kind == KindUpstream {
+			kind = KindTimeout
+			if errors.Is(ctx.Err(), context.Canceled) {
+				kind = KindCanceled
+			}
+		}
+		return nil, &Error{Kind: kind, DeckCode: deckCode, Status: status, Err: err}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.config.BaseURL+url.PathEscape(deckCode), nil)
+	if err != nil {
+		return fail(KindUpstream, 0, err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fail(KindUpstream, 0, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fail(KindNotFound, resp.StatusCode, nil)
+	case resp.StatusCode != http.StatusOK:
+		return fail(KindUpstream, resp.StatusCode, nil)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, s.config.MaxBytes+1))
+	if err != nil {
+		return fail(KindUpstream, resp.StatusCode, err)
+	}
+
+	if int64(len(body)) > s.config.MaxBytes {
+		return fail(KindTooLarge, resp.StatusCode, nil)
+	}
+
+	var deck []*engine.Card
+	if err := json.Unmarshal(body, &deck); err != nil {
+		return fail(KindInvalidResponse, resp.StatusCode, err)
+	}
+
+	return deck, nil
+}
+
+// FromEnv returns the deck source selected by DECKTYPE_DECK_SOURCE:
+//
+//   - http, the default: the deckcards API at DECKTYPE_UPSTREAM_URL,
+//     configured by ConfigFromEnv, behind a Breaker that opens after
+//     DECKTYPE_BREAKER_FAILURES consecutive failures (5 by default) for
+//     DECKTYPE_BREAKER_COOLDOWN (30s by default). Zero failures disables it.
+//   - dir: the JSON fixtures in the directory DECKTYPE_DECK_DIR.
+func FromEnv() (Source, error) {
+	switch kind := os.Getenv("DECKTYPE_DECK_SOURCE"); kind {
+	case "", "http":
+		config, err := ConfigFromEnv()
+		if err != nil {
+			return nil, err
+		}
+
+		failures, cooldown, err := breakerFromEnv()
+		if err != nil {
+			return nil, err
+		}
+
+		return NewBreaker(NewHTTP(config), failures, cooldown), nil
+
+	case "dir":
+		dir := os.Getenv("DECKTYPE_DECK_DIR")
+		if dir == "" {
+			return nil, errors.New("DECKTYPE_DECK_DIR is required with DECKTYPE_DECK_SOURCE=dir")
+		}
+		return NewDir(os.DirFS(dir)), nil
+
+	default:
+		return nil, fmt.Errorf("DECKTYPE_DECK_SOURCE: unknown deck source %q", kind)
+	}
+}
+
+func breakerFromEnv() (failures int, cooldown time.Duration, err error) {
+	failures, cooldown = 5, 30*time.Second
+
+	name := "DECKTYPE_BREAKER_FAILURES"
+	if s := os.Getenv(name); s != "" {
+		failures, err = strconv.Atoi(s)
+		if err != nil || failures < 0 {
+			return 0, 0, fmt.Errorf("%s: invalid number %q", name, s)
+		}
+	}
+
+	name = "DECKTYPE_BREAKER_COOLDOWN"
+	if s := os.Getenv(name); s != "" {
+		cooldown, err = time.ParseDuration(s)
+		if err != nil || cooldown <= 0 {
+			return 0, 0, fmt.Errorf("%s: invalid duration %q", name, s)
+		}
+	}
+
+	return failures, cooldown, nil
+}
+
+// ConfigFromEnv reads the config from DECKTYPE_UPSTREAM_URL,
+// DECKTYPE_UPSTREAM_TIMEOUT, DECKTYPE_UPSTREAM_RETRIES,
+// DECKTYPE_UPSTREAM_BACKOFF and DECKTYPE_UPSTREAM_MAX_BYTES, falling back to
+// DefaultConfig for unset variables. Durations are Go durations such as
+// "5s".
+func ConfigFromEnv() (Config, error) {
+	config := DefaultConfig
+
+	name := "DECKTYPE_UPSTREAM_URL"
+	if s := os.Getenv(name); s != "" {
+		u, err := url.Parse(s)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return Config{}, fmt.Errorf("%s: invalid URL %q", name, s)
+		}
+		config.BaseURL = strings.TrimSuffix(s, "/") + "/"
+	}
+	durations := []struct {
+		name string
+		dst  *time.Duration
+	}{
+		{"DECKTYPE_UPSTREAM_TIMEOUT", &config.Timeout},
+		{"DECKTYPE_UPSTREAM_BACKOFF", &config.Backoff},
+	}
+	for _, d := range durations {
+		if s := os.Getenv(d.name); s != "" {
+			v, err := time.ParseDuration(s)
+			if err != nil || v <= 0 {
+				return Config{}, fmt.Errorf("%s: invalid duration %q", d.name, s)
+			}
+			*d.dst = v
+		}
+	}
+
+	name = "DECKTYPE_UPSTREAM_RETRIES"
+	if s := os.Getenv(name); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 0 {
+			return Config{}, fmt.Errorf("%s: invalid number %q", name, s)
+		}
+		config.Retries = v
+	}
+
+	name = "DECKTYPE_UPSTREAM_MAX_BYTES"
+	if s := os.Getenv(name); s != "" {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || v <= 0 {
+			return Config{}, fmt.Errorf("%s: invalid size %q", name, s)
+		}
+		config.MaxBytes = v
+	}
+
+	return config, nil
+}
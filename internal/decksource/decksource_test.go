@@ -0,0 +1,135 @@
+package decksource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestSource(t *testing.T, handler http.HandlerFunc) *HTTP {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return NewHTTP(Config{
+		BaseURL:  srv.URL + "/",
+		Timeout:  100 * time.Millisecond,
+		Retries:  2,
+		Backoff:  time.Millisecond,
+		MaxBytes: 64,
+	})
+}
+
+func TestFetch(t *testing.T) {
+	s := newTestSource(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/abc" {
+			t.Errorf("path = %s, want /abc", r.URL.Path)
+		}
+		w.Write([]byte(`[{"name":"ドラメシヤ","count":4}]`))
+	})
+
+	deck, err := s.Fetch(context.Background(), "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deck) != 1 || deck[0].Name != "ドラメシヤ" || deck[0].Count != 4 {
+		t.Errorf("deck = %v", deck)
+	}
+}
+
+func TestFetchRetries(t *testing.T) {
+	var calls atomic.Int32
+	s := newTestSource(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`[]`))
+	})
+
+	if _, err := s.Fetch(context.Background(), "abc"); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3", calls.Load())
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		kind    Kind
+		calls   int32
+	}{
+		{
+			name:    "not found",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) },
+			kind:    KindNotFound,
+			calls:   1,
+		},
+		{
+			name:    "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusInternalServerError) },
+			kind:    KindUpstream,
+			calls:   3,
+		},
+		{
+			name:    "timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) { time.Sleep(200 * time.Millisecond) },
+			kind:    KindTimeout,
+			calls:   1,
+		},
+		{
+			name:    "too large",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(strings.Repeat(" ", 65))) },
+			kind:    KindTooLarge,
+			calls:   1,
+		},
+		{
+			name:    "invalid response",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(`{}`)) },
+			kind:    KindInvalidResponse,
+			calls:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			s := newTestSource(t, func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				tt.handler(w, r)
+			})
+
+			_, err := s.Fetch(context.Background(), "abc")
+			if !IsKind(err, tt.kind) {
+				t.Errorf("err = %v, want kind %s", err, tt.kind)
+			}
+
+			if calls.Load() != tt.calls {
+				t.Errorf("calls = %d, want %d", calls.Load(), tt.calls)
+			}
+		})
+	}
+}
+
+func TestFetchCanceled(t *testing.T) {
+	s := newTestSource(t, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	if _, err := s.Fetch(ctx, "abc"); !IsKind(err, KindCanceled) {
+		t.Errorf("err = %v, want kind %s", err, KindCanceled)
+	}
+}
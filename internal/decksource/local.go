@@ -0,0 +1,80 @@
+package decksource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"strings"
+	"sync"
+
+	"github.com/vsrecorder/decktype-api/internal/engine"
+)
+
+// Dir reads deck lists from JSON files named <deck code>.json, in the shape
+// of the deckcards API response.
+type Dir struct {
+	fsys fs.FS
+}
+
+// NewDir returns a deck source reading the fixtures in fsys.
+func NewDir(fsys fs.FS) *Dir {
+	return &Dir{fsys: fsys}
+}
+
+func (s *Dir) Fetch(ctx context.Context, deckCode string) ([]*engine.Card, error) {
+	name := deckCode + ".json"
+	if strings.ContainsAny(deckCode, `/\`) || !fs.ValidPath(name) {
+		return nil, &Error{Kind: KindNotFound, DeckCode: deckCode}
+	}
+
+	data, err := fs.ReadFile(s.fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &Error{Kind: KindNotFound, DeckCode: deckCode}
+	} else if err != nil {
+		return nil, &Error{Kind: KindUpstream, DeckCode: deckCode, Err: err}
+	}
+
+	var deck []*engine.Card
+	if err := json.Unmarshal(data, &deck); err != nil {
+		return nil, &Error{Kind: KindInvalidResponse, DeckCode: deckCode, Err: err}
+	}
+
+	return deck, nil
+}
+
+// Memory holds deck lists in memory, for tests.
+type Memory struct {
+	mu    sync.RWMutex
+	decks map[string][]*engine.Card
+}
+
+// NewMemory returns a deck source holding decks, keyed by deck code.
+func NewMemory(decks map[string][]*engine.Card) *Memory {
+	m := &Memory{decks: make(map[string][]*engine.Card)}
+	for deckCode, deck := range decks {
+		m.Add(deckCode, deck)
+	}
+
+	return m
+}
+
+// Add adds or replaces the deck list of deckCode.
+func (m *Memory) Add(deckCode string, deck []*engine.Card) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.decks[deckCode] = deck
+}
+
+func (m *Memory) Fetch(ctx context.Context, deckCode string) ([]*engine.Card, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	deck, ok := m.decks[deckCode]
+	if !ok {
+		return nil, &Error{Kind: KindNotFound, DeckCode: deckCode}
+	}
+
+	return deck, nil
+}
@@ -0,0 +1,46 @@
+package decksource
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/vsrecorder/decktype-api/internal/engine"
+)
+
+func TestDir(t *testing.T) {
+	s := NewDir(fstest.MapFS{
+		"abc.json": {Data: []byte(`[{"name":"ドラメシヤ","count":4}]`)},
+		"bad.json": {Data: []byte(`{}`)},
+	})
+
+	deck, err := s.Fetch(context.Background(), "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deck) != 1 || deck[0].Name != "ドラメシヤ" {
+		t.Errorf("deck = %v", deck)
+	}
+
+	for deckCode, kind := range map[string]Kind{
+		"missing": KindNotFound,
+		"../abc":  KindNotFound,
+		"bad":     KindInvalidResponse,
+	} {
+		if _, err := s.Fetch(context.Background(), deckCode); !IsKind(err, kind) {
+			t.Errorf("Fetch(%q) = %v, want kind %s", deckCode, err, kind)
+		}
+	}
+}
+
+func TestMemory(t *testing.T) {
+	s := NewMemory(map[string][]*engine.Card{"abc": {{Name: "ドラメシヤ", Count: 4}}})
+
+	if _, err := s.Fetch(context.Background(), "abc"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Fetch(context.Background(), "def"); !IsKind(err, KindNotFound) {
+		t.Errorf("err = %v, want kind %s", err, KindNotFound)
+	}
+}
@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"strings"
+)
+
+const (
+	// aceSpecFile lists the ACE SPEC cards, one name per line. Empty lines
+	// and lines starting with "#" are ignored.
+	aceSpecFile = "acespec.txt"
+
+	// AceSpecSuffix marks ACE SPEC cards in the deckcards API, as in
+	// "ニュートラルセンター(ACE SPEC)".
+	AceSpecSuffix = "(ACE SPEC)"
+
+	// aceSpecGroup is the built-in group of the listed ACE SPEC cards,
+	// under their names with and without the suffix.
+	aceSpecGroup = "acespec"
+)
+
+// LoadAceSpecs reads the ACE SPEC cards listed in fsys, without the suffix.
+// The list is optional; without it only the suffix identifies ACE SPEC
+// cards.
+func LoadAceSpecs(fsys fs.FS) ([]string, error) {
+	data, err := fs.ReadFile(fsys, aceSpecFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var cards []string
+	seen := make(map[string]bool)
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		card := strings.TrimSuffix(line, AceSpecSuffix)
+		if !seen[card] {
+			seen[card] = true
+			cards = append(cards, card)
+		}
+	}
+
+	return cards, sc.Err()
+}
+
+// aceSpecCards returns the cards of the acespec group.
+func aceSpecCards(aceSpecs []string) []string {
+	cards := make([]string, 0, 2*len(aceSpecs))
+	for _, card := range aceSpecs {
+		cards = append(cards, card, card+AceSpecSuffix)
+	}
+
+	return cards
+}
+
+// AceSpec returns the first ACE SPEC card of the deck, or nil if it has
+// none. A card is an ACE SPEC card if its name has the "(ACE SPEC)" suffix
+// or is in the ACE SPEC list.
+func (rs *RuleSet) AceSpec(deck []*Card) *MainCard {
+	for _, card := range deck {
+		if card.Count > 0 && rs.isAceSpec(card.Name) {
+			return &MainCard{
+				Name:     card.Name,
+				ImageURL: card.ImageURL,
+			}
+		}
+	}
+
+	return nil
+}
+
+func (rs *RuleSet) isAceSpec(name string) bool {
+	if strings.HasSuffix(name, AceSpecSuffix) {
+		return true
+	}
+
+	for _, card := range rs.aceSpecs {
+		if card == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,419 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+)
+
+type valueType int
+
+const (
+	intType valueType = iota + 1
+	boolType
+	stringType
+)
+
+func (t valueType) String() string {
+	switch t {
+	case intType:
+		return "number"
+	case boolType:
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+// function is a built-in function of the condition language. Every argument
+// is a card name or, when groups is set, the name of a card group.
+type function struct {
+	result  valueType
+	minArgs int
+	maxArgs int
+	groups  bool
+}
+
+var functions = map[string]function{
+	// count("card") is the number of copies of the card in the deck.
+	"count": {result: intType, minArgs: 1, maxArgs: 1},
+	// sum("a", group, ...) is the total number of copies of the cards.
+	"sum": {result: intType, minArgs: 1, maxArgs: -1, groups: true},
+	// kinds("a", group, ...) is the number of different cards in the deck.
+	"kinds": {result: intType, minArgs: 1, maxArgs: -1, groups: true},
+	// any("a", group, ...) is true when at least one of the cards is in the deck.
+	"any": {result: boolType, minArgs: 1, maxArgs: -1, groups: true},
+	// all("a", group, ...) is true when every card is in the deck.
+	"all": {result: boolType, minArgs: 1, maxArgs: -1, groups: true},
+	// none("a", group, ...) is true when none of the cards is in the deck.
+	"none": {result: boolType, minArgs: 1, maxArgs: -1, groups: true},
+}
+
+// Condition is a compiled rule condition.
+type Condition struct {
+	src  string
+	root node
+}
+
+// Compile parses and type-checks a condition. groups maps the name of each
+// card group the condition may refer to to its cards.
+func Compile(src string, groups map[string][]string) (*Condition, error) {
+	root, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	typ, err := check(root, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	if typ != boolType {
+		return nil, &SyntaxError{Column: root.column(), Msg: fmt.Sprintf("condition must be a boolean, found %s", typ)}
+	}
+
+	return &Condition{src: src, root: root}, nil
+}
+
+func (c *Condition) String() string {
+	return c.src
+}
+
+// Eval reports whether the card counts satisfy the condition.
+func (c *Condition) Eval(cardlist map[string]int) bool {
+	return evalBool(c.root, cardlist)
+}
+
+// Margin returns by how many copies the deck exceeds the lower bounds of
+// the comparisons that make the condition true. Comparisons under "!" are
+// not counted.
+func (c *Condition) Margin(cardlist map[string]int) int {
+	return margin(c.root, cardlist)
+}
+
+func margin(n node, cardlist map[string]int) int {
+	b, ok := n.(*binaryExpr)
+	if !ok {
+		return 0
+	}
+
+	switch b.op {
+	case tokAnd:
+		return margin(b.x, cardlist) + margin(b.y, cardlist)
+	case tokOr:
+		total := 0
+		if evalBool(b.x, cardlist) {
+			total += margin(b.x, cardlist)
+		}
+		if evalBool(b.y, cardlist) {
+			total += margin(b.y, cardlist)
+		}
+		return total
+	}
+
+	op, count, limit, ok := bounds(b)
+	if !ok {
+		return 0
+	}
+
+	x := evalInt(count, cardlist)
+	switch op {
+	case tokGe:
+		return max(x-limit.value, 0)
+	case tokGt:
+		return max(x-limit.value-1, 0)
+	}
+
+	return 0
+}
+
+// bounds rewrites a comparison between a number and an integer literal so
+// that the literal is on the right, e.g. 2 <= count("a") to count("a") >= 2.
+// ok is false if neither side is a literal.
+func bounds(b *binaryExpr) (op tokenKind, count node, limit *intLit, ok bool) {
+	if limit, ok := b.y.(*intLit); ok {
+		return b.op, b.x, limit, true
+	}
+
+	limit, ok = b.x.(*intLit)
+	if !ok {
+		return 0, nil, nil, false
+	}
+
+	op = b.op
+	switch op {
+	case tokLt:
+		op = tokGt
+	case tokLe:
+		op = tokGe
+	case tokGt:
+		op = tokLt
+	case tokGe:
+		op = tokLe
+	}
+
+	return op, b.y, limit, true
+}
+
+func check(n node, groups map[string][]string) (valueType, error) {
+	switch n := n.(type) {
+	case *intLit:
+		return intType, nil
+
+	case *strLit:
+		return stringType, nil
+
+	case *groupRef:
+		return 0, &SyntaxError{Column: n.col, Msg: fmt.Sprintf("group %s can only be used as a function argument", n.name)}
+
+	case *notExpr:
+		if err := expectType(n.x, boolType, `operand of "!"`, groups); err != nil {
+			return 0, err
+		}
+		return boolType, nil
+
+	case *binaryExpr:
+		want, what := intType, "operand of comparison"
+		if n.op == tokAnd || n.op == tokOr {
+			want, what = boolType, `operand of "&&" and "||"`
+		}
+		if err := expectType(n.x, want, what, groups); err != nil {
+			return 0, err
+		}
+		if err := expectType(n.y, want, what, groups); err != nil {
+			return 0, err
+		}
+		return boolType, nil
+
+	case *call:
+		fn, ok := functions[n.name]
+		if !ok {
+			return 0, &SyntaxError{Column: n.col, Msg: fmt.Sprintf("unknown function %q", n.name)}
+		}
+		if len(n.args) < fn.minArgs || fn.maxArgs >= 0 && len(n.args) > fn.maxArgs {
+			return 0, &SyntaxError{Column: n.col, Msg: fmt.Sprintf("wrong number of arguments to %s", n.name)}
+		}
+		n.cards = nil
+		seen := make(map[string]bool)
+		add := func(card string) {
+			if !seen[card] {
+				seen[card] = true
+				n.cards = append(n.cards, card)
+			}
+		}
+		for _, arg := range n.args {
+			switch arg := arg.(type) {
+			case *strLit:
+				add(arg.value)
+				continue
+			case *groupRef:
+				if fn.groups {
+					cards, ok := groups[arg.name]
+					if !ok {
+						return 0, &SyntaxError{Column: arg.col, Msg: fmt.Sprintf("unknown group %s", arg.name)}
+					}
+					for _, card := range cards {
+						add(card)
+					}
+					continue
+				}
+			}
+			what := "a card name"
+			if fn.groups {
+				what = "a card name or group"
+			}
+			return 0, &SyntaxError{Column: arg.column(), Msg: fmt.Sprintf("argument of %s must be %s", n.name, what)}
+		}
+		return fn.result, nil
+	}
+
+	panic("unreachable")
+}
+
+func expectType(n node, want valueType, what string, groups map[string][]string) error {
+	typ, err := check(n, groups)
+	if err != nil {
+		return err
+	}
+
+	if typ != want {
+		return &SyntaxError{Column: n.column(), Msg: fmt.Sprintf("%s must be a %s, found %s", what, want, typ)}
+	}
+
+	return nil
+}
+
+func evalBool(n node, cardlist map[string]int) bool {
+	switch n := n.(type) {
+	case *notExpr:
+		return !evalBool(n.x, cardlist)
+
+	case *binaryExpr:
+		switch n.op {
+		case tokAnd:
+			return evalBool(n.x, cardlist) && evalBool(n.y, cardlist)
+		case tokOr:
+			return evalBool(n.x, cardlist) || evalBool(n.y, cardlist)
+		}
+		return compare(n.op, evalInt(n.x, cardlist), evalInt(n.y, cardlist))
+
+	case *call:
+		kinds := countKinds(n.cards, cardlist)
+		switch n.name {
+		case "any":
+			return kinds > 0
+		case "all":
+			return kinds == len(n.cards)
+		case "none":
+			return kinds == 0
+		}
+	}
+
+	panic("unreachable")
+}
+
+func evalInt(n node, cardlist map[string]int) int {
+	switch n := n.(type) {
+	case *intLit:
+		return n.value
+
+	case *call:
+		switch n.name {
+		case "kinds":
+			return countKinds(n.cards, cardlist)
+		default:
+			total := 0
+			for _, card := range n.cards {
+				total += cardlist[card]
+			}
+			return total
+		}
+	}
+
+	panic("unreachable")
+}
+
+// countKinds returns how many of the cards are in the deck.
+func countKinds(cards []string, cardlist map[string]int) int {
+	kinds := 0
+	for _, card := range cards {
+		if cardlist[card] > 0 {
+			kinds++
+		}
+	}
+
+	return kinds
+}
+
+func compare(op tokenKind, x, y int) bool {
+	switch op {
+	case tokEq:
+		return x == y
+	case tokNe:
+		return x != y
+	case tokLt:
+		return x < y
+	case tokLe:
+		return x <= y
+	case tokGt:
+		return x > y
+	default:
+		return x >= y
+	}
+}
+
+// Cards returns the card names the condition refers to, in order of first
+// appearance.
+func (c *Condition) Cards() []string {
+	var cards []string
+	walk(c.root, func(n node) {
+		if call, ok := n.(*call); ok {
+			for _, card := range call.cards {
+				if !slices.Contains(cards, card) {
+					cards = append(cards, card)
+				}
+			}
+		}
+	})
+
+	return cards
+}
+
+// groups returns the names of the groups the condition refers to.
+func (c *Condition) groups() []string {
+	var groups []string
+	walk(c.root, func(n node) {
+		if g, ok := n.(*groupRef); ok && !slices.Contains(groups, g.name) {
+			groups = append(groups, g.name)
+		}
+	})
+
+	return groups
+}
+
+// points adds to points, for every card of the condition, the counts at
+// which a comparison of the condition can change its result.
+func (c *Condition) points(points map[string][]int) map[string][]int {
+	if points == nil {
+		points = make(map[string][]int)
+	}
+
+	add := func(card string, counts ...int) {
+		for _, n := range counts {
+			if n >= 0 && !slices.Contains(points[card], n) {
+				points[card] = append(points[card], n)
+			}
+		}
+	}
+
+	walk(c.root, func(n node) {
+		switch n := n.(type) {
+		case *call:
+			for _, card := range n.cards {
+				add(card, 0, 1)
+			}
+
+		case *binaryExpr:
+			if n.op == tokAnd || n.op == tokOr {
+				return
+			}
+			x, xok := n.x.(*call)
+			y, yok := n.y.(*intLit)
+			if !xok || !yok {
+				x, xok = n.y.(*call)
+				y, yok = n.x.(*intLit)
+			}
+			if xok && yok {
+				for _, card := range x.cards {
+					add(card, y.value-1, y.value, y.value+1)
+				}
+			} else if xok {
+				for _, card := range x.cards {
+					add(card, 2, 3, 4)
+				}
+			}
+		}
+	})
+
+	for card := range points {
+		slices.Sort(points[card])
+	}
+
+	return points
+}
+
+// walk calls fn for n and every node below it.
+func walk(n node, fn func(node)) {
+	fn(n)
+
+	switch n := n.(type) {
+	case *notExpr:
+		walk(n.x, fn)
+	case *binaryExpr:
+		walk(n.x, fn)
+		walk(n.y, fn)
+	case *call:
+		for _, arg := range n.args {
+			walk(arg, fn)
+		}
+	}
+}
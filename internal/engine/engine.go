@@ -0,0 +1,243 @@
+package engine
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+type Card struct {
+	Name      string `json:"name"`
+	DetailURL string `json:"detail_url"`
+	ImageURL  string `json:"image_url"`
+	Count     int    `json:"count"`
+}
+
+type MainCard struct {
+	Name     string `json:"name"`
+	ImageURL string `json:"image_url"`
+}
+
+// DeckType is a matched archetype. Title is the title of the archetype, or
+// of its variant if the variant has one of its own; Archetype is always the
+// title of the archetype, so results can be aggregated by archetype.
+type DeckType struct {
+	Title      string      `json:"title"`
+	Archetype  string      `json:"archetype"`
+	MainCards  []*MainCard `json:"main_cards"`
+	SubTitle   string      `json:"sub_title,omitempty"`
+	SubCards   []*MainCard `json:"sub_cards,omitempty"`
+	Score      int         `json:"score"`
+	Confidence float64     `json:"confidence"`
+}
+
+// Result is the classification of a deck under an environment: the
+// archetype that matched best and the other matching archetypes, best first.
+// Primary is nil if no rule matched. Version is the version of the rule set
+// that classified the deck. AceSpec is the ACE SPEC card of the deck, if any.
+type Result struct {
+	Environment string      `json:"environment"`
+	Version     string      `json:"version"`
+	Primary     *DeckType   `json:"primary"`
+	Secondaries []*DeckType `json:"secondaries"`
+	AceSpec     *MainCard   `json:"acespec_card"`
+}
+
+// RuleSet is the list of archetype rules of one environment, in the order
+// they are evaluated, and the card groups their conditions refer to. Rules
+// and groups inherited from the environment it extends are included.
+type RuleSet struct {
+	Environment     string              `yaml:"environment"`
+	Extends         string              `yaml:"extends,omitempty"`
+	Name            string              `yaml:"name"`
+	Set             string              `yaml:"set"`
+	RegulationMarks []string            `yaml:"regulation_marks,flow"`
+	Start           Date                `yaml:"start"`
+	Groups          map[string][]string `yaml:"groups,omitempty"`
+	Rules           []*Rule             `yaml:"rules"`
+
+	// Version identifies the content of the rule set. It changes whenever
+	// the resolved rules or metadata of the environment, or the ACE SPEC
+	// list, change.
+	Version string `yaml:"-"`
+
+	aceSpecs []string
+}
+
+// Rule describes one archetype: the title reported to clients, the
+// condition a deck has to satisfy and the cards shown as its main cards.
+// Its ID, which defaults to the title, identifies it in extending
+// environments. An entry of MainCards that names a card group stands for
+// the cards of the group.
+type Rule struct {
+	ID        string     `yaml:"id,omitempty"`
+	Title     string     `yaml:"title"`
+	When      string     `yaml:"when"`
+	MainCards []string   `yaml:"main_cards"`
+	Variants  []*Variant `yaml:"variants,omitempty"`
+
+	cond      *Condition
+	mainCards []string
+}
+
+// Variant is a sub-archetype of a rule, such as the build of an archetype
+// that adds a particular engine. The variants of a rule are evaluated in
+// order once the rule matched, and the first one the deck satisfies sets the
+// sub-title and sub-cards of the deck type. A variant with a title of its
+// own, such as a build known under another name, also replaces the title,
+// and one with main cards of its own replaces the main cards of the rule.
+// Like main cards, sub-cards may name card groups.
+type Variant struct {
+	Title     string   `yaml:"title,omitempty"`
+	SubTitle  string   `yaml:"sub_title"`
+	When      string   `yaml:"when"`
+	MainCards []string `yaml:"main_cards,omitempty"`
+	SubCards  []string `yaml:"sub_cards"`
+
+	cond      *Condition
+	mainCards []string
+	subCards  []string
+}
+
+// clone returns a copy of the rule that shares no variants with it, so the
+// copy can be compiled against the groups of another environment.
+func (r *Rule) clone() *Rule {
+	c := *r
+	c.Variants = make([]*Variant, len(r.Variants))
+	for i, v := range r.Variants {
+		vc := *v
+		c.Variants[i] = &vc
+	}
+
+	return &c
+}
+
+// Classify ranks the deck types of every rule the deck satisfies.
+//
+// The score of a deck type is the number of different cards of its main
+// cards and of the sub-cards of its variant in the deck, plus the margin of
+// its condition: the number of copies by which the deck exceeds the lower
+// bounds ("count(...) >= 2") of the comparisons that made the condition
+// true. Comparisons under "!" add nothing. The confidence of a deck type is
+// its share of the total score of all matching deck types.
+//
+// Deck types are ordered by score, highest first. Ties are broken by margin
+// and then by the order of the rules in the rule file.
+func (rs *RuleSet) Classify(deck []*Card) *Result {
+	cardlist := countCards(deck)
+
+	type match struct {
+		deckType *DeckType
+		margin   int
+	}
+
+	var matches []match
+	total := 0
+	for _, rule := range rs.Rules {
+		if !rule.cond.Eval(cardlist) {
+			continue
+		}
+
+		deckType := analyze(rule.Title, deck, rule.mainCards)
+		deckType.Archetype = rule.Title
+		for _, variant := range rule.Variants {
+			if variant.cond.Eval(cardlist) {
+				if variant.Title != "" {
+					deckType.Title = variant.Title
+				}
+				if variant.mainCards != nil {
+					deckType.MainCards = findCards(deck, variant.mainCards)
+				}
+				deckType.SubTitle = variant.SubTitle
+				deckType.SubCards = findCards(deck, variant.subCards)
+				break
+			}
+		}
+
+		margin := rule.cond.Margin(cardlist)
+		deckType.Score = cardsFound(deckType) + margin
+		total += deckType.Score
+
+		matches = append(matches, match{deckType: deckType, margin: margin})
+	}
+
+	slices.SortStableFunc(matches, func(a, b match) int {
+		if c := cmp.Compare(b.deckType.Score, a.deckType.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.margin, a.margin)
+	})
+
+	result := &Result{
+		Environment: rs.Environment,
+		Version:     rs.Version,
+		Secondaries: []*DeckType{},
+		AceSpec:     rs.AceSpec(deck),
+	}
+	for i, m := range matches {
+		if total > 0 {
+			m.deckType.Confidence = math.Round(float64(m.deckType.Score)/float64(total)*100) / 100
+		}
+
+		if i == 0 {
+			result.Primary = m.deckType
+		} else {
+			result.Secondaries = append(result.Secondaries, m.deckType)
+		}
+	}
+
+	return result
+}
+
+// cardsFound returns the number of different cards among the main cards and
+// sub-cards of the deck type, which may share cards.
+func cardsFound(deckType *DeckType) int {
+	found := len(deckType.MainCards)
+	for _, sub := range deckType.SubCards {
+		if !slices.ContainsFunc(deckType.MainCards, func(main *MainCard) bool { return main.Name == sub.Name }) {
+			found++
+		}
+	}
+
+	return found
+}
+
+func countCards(deck []*Card) map[string]int {
+	cardlist := make(map[string]int)
+	for _, card := range deck {
+		cardlist[card.Name] += card.Count
+	}
+
+	return cardlist
+}
+
+func analyze(title string, deck []*Card, cards []string) *DeckType {
+	deckType := &DeckType{
+		Title:     title,
+		MainCards: findCards(deck, cards),
+	}
+
+	return deckType
+}
+
+// findCards returns the cards that are in the deck, in the order of cards.
+func findCards(deck []*Card, cards []string) []*MainCard {
+	var found []*MainCard
+
+	for _, cardname := range cards {
+		for _, card := range deck {
+			if card.Name == cardname {
+				found = append(
+					found,
+					&MainCard{
+						Name:     card.Name,
+						ImageURL: card.ImageURL,
+					},
+				)
+				break
+			}
+		}
+	}
+
+	return found
+}
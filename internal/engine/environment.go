@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const dateLayout = "2006-01-02"
+
+// Date is a calendar day, written as YYYY-MM-DD in rule files and
+// responses.
+type Date struct {
+	time.Time
+}
+
+// ParseDate parses a YYYY-MM-DD date.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, err
+	}
+
+	return Date{t}, nil
+}
+
+func (d Date) String() string {
+	return d.Format(dateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d Date) MarshalYAML() (any, error) {
+	return d.String(), nil
+}
+
+func (d *Date) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return err
+	}
+
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
+
+// Environment describes a format decks are classified under: the set
+// released at its start, the regulation marks legal in it, the days it was
+// active and the version of its rules.
+type Environment struct {
+	ID              string   `json:"id"`
+	Version         string   `json:"version"`
+	Name            string   `json:"name"`
+	Set             string   `json:"set"`
+	RegulationMarks []string `json:"regulation_marks"`
+	Start           Date     `json:"start"`
+
+	// End is the last day of the environment, the day before the next one
+	// starts. It is nil for the newest environment.
+	End *Date `json:"end,omitempty"`
+}
+
+// Environments returns the environments of the rule sets, oldest first.
+func Environments(ruleSets map[string]*RuleSet) []*Environment {
+	envs := make([]*Environment, 0, len(ruleSets))
+	for _, rs := range ruleSets {
+		envs = append(envs, &Environment{
+			ID:              rs.Environment,
+			Version:         rs.Version,
+			Name:            rs.Name,
+			Set:             rs.Set,
+			RegulationMarks: rs.RegulationMarks,
+			Start:           rs.Start,
+		})
+	}
+
+	slices.SortFunc(envs, func(a, b *Environment) int {
+		return a.Start.Compare(b.Start.Time)
+	})
+
+	for i := 1; i < len(envs); i++ {
+		end := Date{envs[i].Start.AddDate(0, 0, -1)}
+		envs[i-1].End = &end
+	}
+
+	return envs
+}
+
+// EnvironmentAt returns the environment of envs, sorted oldest first, that
+// was active on date, or nil if date is before the oldest one.
+func EnvironmentAt(envs []*Environment, date Date) *Environment {
+	for i := len(envs) - 1; i >= 0; i-- {
+		if !envs[i].Start.After(date.Time) {
+			return envs[i]
+		}
+	}
+
+	return nil
+}
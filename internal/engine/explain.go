@@ -0,0 +1,178 @@
+package engine
+
+// CardCount is the number of copies of a card in a deck.
+type CardCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// ConditionExplanation shows how a part of a condition was evaluated.
+// Comparisons of a card count with a number report the count as Actual and
+// the number as Threshold; function calls report the counts of their cards.
+type ConditionExplanation struct {
+	Expr      string                  `json:"expr"`
+	Matched   bool                    `json:"matched"`
+	Actual    *int                    `json:"actual,omitempty"`
+	Op        string                  `json:"op,omitempty"`
+	Threshold *int                    `json:"threshold,omitempty"`
+	Cards     []*CardCount            `json:"cards,omitempty"`
+	Children  []*ConditionExplanation `json:"children,omitempty"`
+}
+
+// MainCardExplanation tells whether a main card of a rule is in the deck.
+type MainCardExplanation struct {
+	Name  string `json:"name"`
+	Found bool   `json:"found"`
+}
+
+// RuleExplanation shows why a rule did or did not match a deck.
+type RuleExplanation struct {
+	ID        string                 `json:"id"`
+	Title     string                 `json:"title"`
+	When      string                 `json:"when"`
+	Matched   bool                   `json:"matched"`
+	Condition *ConditionExplanation  `json:"condition"`
+	MainCards []*MainCardExplanation `json:"main_cards"`
+	Variants  []*VariantExplanation  `json:"variants,omitempty"`
+}
+
+// VariantExplanation shows how the condition of a variant evaluated. Applied
+// is set for the variant that set the titles of the deck type: the first one
+// that matched, if the rule matched. MainCards is empty unless the variant
+// replaces the main cards of the rule.
+type VariantExplanation struct {
+	Title     string                 `json:"title,omitempty"`
+	SubTitle  string                 `json:"sub_title"`
+	When      string                 `json:"when"`
+	Matched   bool                   `json:"matched"`
+	Applied   bool                   `json:"applied"`
+	Condition *ConditionExplanation  `json:"condition"`
+	MainCards []*MainCardExplanation `json:"main_cards,omitempty"`
+	SubCards  []*MainCardExplanation `json:"sub_cards"`
+}
+
+// Explanation shows how every rule of an environment was evaluated against
+// a deck.
+type Explanation struct {
+	Environment string             `json:"environment"`
+	Rules       []*RuleExplanation `json:"rules"`
+}
+
+// Explain evaluates every rule of rs against the deck and reports, for each
+// rule and each of its variants, the result of every part of its condition
+// and which of its main cards or sub-cards are in the deck.
+func (rs *RuleSet) Explain(deck []*Card) *Explanation {
+	cardlist := countCards(deck)
+
+	explanations := make([]*RuleExplanation, 0, len(rs.Rules))
+	for _, rule := range rs.Rules {
+		e := &RuleExplanation{
+			ID:        rule.ID,
+			Title:     rule.Title,
+			When:      rule.When,
+			Matched:   rule.cond.Eval(cardlist),
+			Condition: explain(rule.cond.root, cardlist),
+		}
+
+		e.MainCards = cardsFoundIn(rule.mainCards, cardlist)
+
+		applied := !e.Matched
+		for _, variant := range rule.Variants {
+			v := &VariantExplanation{
+				Title:     variant.Title,
+				SubTitle:  variant.SubTitle,
+				When:      variant.When,
+				Matched:   variant.cond.Eval(cardlist),
+				Condition: explain(variant.cond.root, cardlist),
+				MainCards: cardsFoundIn(variant.mainCards, cardlist),
+				SubCards:  cardsFoundIn(variant.subCards, cardlist),
+			}
+			if v.Matched && !applied {
+				v.Applied, applied = true, true
+			}
+			e.Variants = append(e.Variants, v)
+		}
+
+		explanations = append(explanations, e)
+	}
+
+	return &Explanation{
+		Environment: rs.Environment,
+		Rules:       explanations,
+	}
+}
+
+// cardsFoundIn reports which of the cards are in the deck.
+func cardsFoundIn(cards []string, cardlist map[string]int) []*MainCardExplanation {
+	var found []*MainCardExplanation
+	for _, card := range cards {
+		found = append(found, &MainCardExplanation{
+			Name:  card,
+			Found: cardlist[card] > 0,
+		})
+	}
+
+	return found
+}
+
+func explain(n node, cardlist map[string]int) *ConditionExplanation {
+	e := &ConditionExplanation{
+		Expr:    format(n),
+		Matched: evalBool(n, cardlist),
+	}
+
+	switch n := n.(type) {
+	case *notExpr:
+		e.Op = "!"
+		e.Children = []*ConditionExplanation{explain(n.x, cardlist)}
+
+	case *binaryExpr:
+		if n.op == tokAnd || n.op == tokOr {
+			e.Op = operatorText(n.op)
+			for _, operand := range operands(n, n.op) {
+				e.Children = append(e.Children, explain(operand, cardlist))
+			}
+			break
+		}
+
+		op, count, limit, ok := bounds(n)
+		if !ok {
+			e.Op = operatorText(n.op)
+			e.Cards = cardCounts(n, cardlist)
+			break
+		}
+		actual, threshold := evalInt(count, cardlist), limit.value
+		e.Actual = &actual
+		e.Op = operatorText(op)
+		e.Threshold = &threshold
+		e.Cards = cardCounts(count, cardlist)
+
+	case *call:
+		e.Cards = cardCounts(n, cardlist)
+	}
+
+	return e
+}
+
+// cardCounts returns the counts of the cards n refers to.
+func cardCounts(n node, cardlist map[string]int) []*CardCount {
+	var counts []*CardCount
+	walk(n, func(n node) {
+		if call, ok := n.(*call); ok {
+			for _, card := range call.cards {
+				counts = append(counts, &CardCount{Name: card, Count: cardlist[card]})
+			}
+		}
+	})
+
+	return counts
+}
+
+// operands returns the operands of a chain of op, e.g. a, b and c for
+// a && b && c.
+func operands(n node, op tokenKind) []node {
+	if b, ok := n.(*binaryExpr); ok && b.op == op {
+		return append(operands(b.x, op), operands(b.y, op)...)
+	}
+	return []node{n}
+}
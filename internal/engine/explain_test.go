@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExplainVariants(t *testing.T) {
+	ruleSets, err := Load(ruleFS(map[string]string{"x.yaml": header("x", 1) + `
+rules:
+  - title: "トドロクツキex"
+    when: count("トドロクツキex") >= 2
+    main_cards: ["トドロクツキex", "モモワロウ"]
+    variants:
+      - sub_title: "ストリンダー"
+        when: count("ストリンダー") >= 2
+        sub_cards: ["ストリンダー"]
+      - title: "毒トドロクツキ"
+        sub_title: "モモワロウ/アラブルタケ"
+        when: count("モモワロウ") >= 2 && count("アラブルタケ") >= 2
+        sub_cards: ["モモワロウ", "アラブルタケ"]
+      - sub_title: "モモワロウ"
+        when: count("モモワロウ") >= 1
+        sub_cards: ["モモワロウ"]
+`}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := ruleSets["x"].Explain([]*Card{
+		{Name: "トドロクツキex", Count: 3},
+		{Name: "モモワロウ", Count: 2},
+		{Name: "アラブルタケ", Count: 2},
+	})
+
+	variants := e.Rules[0].Variants
+	if len(variants) != 3 {
+		t.Fatalf("variants = %d, want 3", len(variants))
+	}
+
+	for i, want := range []struct{ matched, applied bool }{{false, false}, {true, true}, {true, false}} {
+		if v := variants[i]; v.Matched != want.matched || v.Applied != want.applied {
+			t.Errorf("variant %q: matched %v, applied %v, want %v, %v", v.SubTitle, v.Matched, v.Applied, want.matched, want.applied)
+		}
+	}
+
+	if v := variants[1]; v.Title != "毒トドロクツキ" || len(v.SubCards) != 2 || !v.SubCards[1].Found || v.Condition.Op != "&&" {
+		t.Errorf("variant = %+v", v)
+	}
+
+	// No variant applies when the rule does not match.
+	e = ruleSets["x"].Explain([]*Card{{Name: "モモワロウ", Count: 2}, {Name: "アラブルタケ", Count: 2}})
+	for _, v := range e.Rules[0].Variants {
+		if v.Applied {
+			t.Errorf("variant %q applied to a rule that did not match", v.SubTitle)
+		}
+	}
+}
+
+func TestClassifyScoresSharedCardsOnce(t *testing.T) {
+	ruleSets, err := Load(ruleFS(map[string]string{"x.yaml": header("x", 1) + `
+rules:
+  - title: "トドロクツキex"
+    when: count("トドロクツキex") >= 2
+    main_cards: ["トドロクツキex", "モモワロウ", "アラブルタケ"]
+    variants:
+      - title: "毒トドロクツキ"
+        sub_title: "モモワロウ/アラブルタケ"
+        when: count("モモワロウ") >= 2
+        sub_cards: ["モモワロウ", "アラブルタケ"]
+`}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := ruleSets["x"].Classify([]*Card{
+		{Name: "トドロクツキex", Count: 2},
+		{Name: "モモワロウ", Count: 2},
+		{Name: "アラブルタケ", Count: 2},
+	})
+	if p := result.Primary; p.Title != "毒トドロクツキ" || len(p.MainCards) != 3 || len(p.SubCards) != 2 || p.Score != 3 {
+		t.Errorf("primary = %s with %d main cards, %d sub-cards and score %d, want 毒トドロクツキ with 3, 2 and 3",
+			p.Title, len(p.MainCards), len(p.SubCards), p.Score)
+	}
+}
+
+func TestVariantMainCards(t *testing.T) {
+	ruleSets, err := Load(ruleFS(map[string]string{"x.yaml": header("x", 1) + `
+rules:
+  - title: "ヒビキのホウオウex"
+    when: count("ヒビキのホウオウex") >= 2
+    main_cards: ["ヒビキのホウオウex", "ヒビキのマグカルゴ"]
+    variants:
+      - title: "ひおくりバレット"
+        sub_title: "グレンアルマ"
+        when: count("グレンアルマ") >= 2
+        main_cards: ["ヒビキのホウオウex", "グレンアルマ", "テツノカイナex"]
+        sub_cards: ["グレンアルマ"]
+`}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deck := []*Card{
+		{Name: "ヒビキのホウオウex", Count: 3},
+		{Name: "ヒビキのマグカルゴ", Count: 1},
+		{Name: "テツノカイナex", Count: 1},
+	}
+	for _, tt := range []struct {
+		glen int
+		want []string
+	}{
+		{0, []string{"ヒビキのホウオウex", "ヒビキのマグカルゴ"}},
+		{2, []string{"ヒビキのホウオウex", "グレンアルマ", "テツノカイナex"}},
+	} {
+		cards := append(deck, &Card{Name: "グレンアルマ", Count: tt.glen})
+		p := ruleSets["x"].Classify(cards).Primary
+
+		var got []string
+		for _, card := range p.MainCards {
+			got = append(got, card.Name)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: main cards = %v, want %v", p.Title, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,429 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SyntaxError reports a problem in a rule condition. Column is the 1-based
+// position, counted in characters, of the offending token.
+type SyntaxError struct {
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Msg)
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokInt
+	tokString
+	tokIdent
+	tokLParen
+	tokRParen
+	tokComma
+	tokNot
+	tokAnd
+	tokOr
+	tokEq
+	tokNe
+	tokLt
+	tokLe
+	tokGt
+	tokGe
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	col  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of condition"
+	}
+	return strconv.Quote(t.text)
+}
+
+// format renders n in the syntax of the condition language.
+func format(n node) string {
+	switch n := n.(type) {
+	case *intLit:
+		return strconv.Itoa(n.value)
+	case *strLit:
+		return strconv.Quote(n.value)
+	case *groupRef:
+		return n.name
+	case *call:
+		args := make([]string, len(n.args))
+		for i, arg := range n.args {
+			args[i] = format(arg)
+		}
+		return n.name + "(" + strings.Join(args, ", ") + ")"
+	case *notExpr:
+		return "!" + formatOperand(n.x, tokNot)
+	case *binaryExpr:
+		return formatOperand(n.x, n.op) + " " + operatorText(n.op) + " " + formatOperand(n.y, n.op)
+	}
+
+	panic("unreachable")
+}
+
+// formatOperand formats an operand of op, in parentheses if it binds less
+// tightly than op.
+func formatOperand(n node, op tokenKind) string {
+	if b, ok := n.(*binaryExpr); ok && precedence(b.op) < precedence(op) {
+		return "(" + format(n) + ")"
+	}
+	return format(n)
+}
+
+func precedence(op tokenKind) int {
+	switch op {
+	case tokOr:
+		return 1
+	case tokAnd:
+		return 2
+	case tokNot:
+		return 4
+	default:
+		return 3
+	}
+}
+
+func operatorText(kind tokenKind) string {
+	for _, op := range operators {
+		if op.kind == kind {
+			return op.text
+		}
+	}
+	return ""
+}
+
+var operators = []struct {
+	text string
+	kind tokenKind
+}{
+	{"&&", tokAnd},
+	{"||", tokOr},
+	{"==", tokEq},
+	{"!=", tokNe},
+	{"<=", tokLe},
+	{">=", tokGe},
+	{"<", tokLt},
+	{">", tokGt},
+	{"!", tokNot},
+	{"(", tokLParen},
+	{")", tokRParen},
+	{",", tokComma},
+}
+
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+
+	col := 1
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRuneInString(src[i:])
+
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+			col++
+			continue
+
+		case r >= '0' && r <= '9':
+			j := i
+			for j < len(src) && src[j] >= '0' && src[j] <= '9' {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokInt, text: src[i:j], col: col})
+			col += j - i
+			i = j
+			continue
+
+		case r == '_' || (r < utf8.RuneSelf && unicode.IsLetter(r)):
+			j := i
+			for j < len(src) && (src[j] == '_' || src[j] < utf8.RuneSelf && (unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j])))) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[i:j], col: col})
+			col += j - i
+			i = j
+			continue
+
+		case r == '"':
+			var sb strings.Builder
+			start := col
+			j := i + 1
+			col++
+			closed := false
+			for j < len(src) {
+				c, n := utf8.DecodeRuneInString(src[j:])
+				j += n
+				col++
+				if c == '"' {
+					closed = true
+					break
+				}
+				if c == '\\' && j < len(src) {
+					c, n = utf8.DecodeRuneInString(src[j:])
+					j += n
+					col++
+				}
+				sb.WriteRune(c)
+			}
+			if !closed {
+				return nil, &SyntaxError{Column: start, Msg: "unterminated string"}
+			}
+			tokens = append(tokens, token{kind: tokString, text: sb.String(), col: start})
+			i = j
+			continue
+		}
+
+		matched := false
+		for _, op := range operators {
+			if strings.HasPrefix(src[i:], op.text) {
+				tokens = append(tokens, token{kind: op.kind, text: op.text, col: col})
+				i += len(op.text)
+				col += len(op.text)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, &SyntaxError{Column: col, Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, col: col}), nil
+}
+
+// node is an element of a parsed condition.
+type node interface {
+	column() int
+}
+
+type intLit struct {
+	col   int
+	value int
+}
+
+type strLit struct {
+	col   int
+	value string
+}
+
+type groupRef struct {
+	col  int
+	name string
+}
+
+type call struct {
+	col  int
+	name string
+	args []node
+
+	// cards holds the card names of the arguments, with groups expanded.
+	// It is filled in by check.
+	cards []string
+}
+
+type notExpr struct {
+	col int
+	x   node
+}
+
+type binaryExpr struct {
+	col int
+	op  tokenKind
+	x   node
+	y   node
+}
+
+func (n *intLit) column() int     { return n.col }
+func (n *strLit) column() int     { return n.col }
+func (n *groupRef) column() int   { return n.col }
+func (n *call) column() int       { return n.col }
+func (n *notExpr) column() int    { return n.col }
+func (n *binaryExpr) column() int { return n.col }
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, &SyntaxError{Column: t.col, Msg: fmt.Sprintf("expected %s, found %s", what, t)}
+	}
+	return t, nil
+}
+
+// parse parses a condition. The grammar, from lowest to highest precedence:
+//
+//	or         = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | comparison
+//	comparison = operand [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) operand ]
+//	operand    = int | string | group | call | "(" or ")"
+//	group      = ident
+//	call       = ident "(" [ or { "," or } ] ")"
+func parse(src string) (node, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, &SyntaxError{Column: t.col, Msg: fmt.Sprintf("unexpected %s", t)}
+	}
+
+	return n, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	x, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokOr {
+		t := p.next()
+		y, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryExpr{col: t.col, op: tokOr, x: x, y: y}
+	}
+
+	return x, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokAnd {
+		t := p.next()
+		y, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryExpr{col: t.col, op: tokAnd, x: x, y: y}
+	}
+
+	return x, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.peek().kind == tokNot {
+		t := p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{col: t.col, x: x}, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	x, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := p.peek(); t.kind {
+	case tokEq, tokNe, tokLt, tokLe, tokGt, tokGe:
+		p.next()
+		y, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryExpr{col: t.col, op: t.kind, x: x, y: y}, nil
+	}
+
+	return x, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokInt:
+		v, err := strconv.Atoi(t.text)
+		if err != nil {
+			return nil, &SyntaxError{Column: t.col, Msg: fmt.Sprintf("invalid number %s", t)}
+		}
+		return &intLit{col: t.col, value: v}, nil
+
+	case tokString:
+		return &strLit{col: t.col, value: t.text}, nil
+
+	case tokIdent:
+		if p.peek().kind != tokLParen {
+			return &groupRef{col: t.col, name: t.text}, nil
+		}
+		p.next()
+		c := &call{col: t.col, name: t.text}
+		if p.peek().kind != tokRParen {
+			for {
+				arg, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				c.args = append(c.args, arg)
+				if p.peek().kind != tokComma {
+					break
+				}
+				p.next()
+			}
+		}
+		if _, err := p.expect(tokRParen, `"," or ")"`); err != nil {
+			return nil, err
+		}
+		return c, nil
+
+	case tokLParen:
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, `")"`); err != nil {
+			return nil, err
+		}
+		return x, nil
+	}
+
+	return nil, &SyntaxError{Column: t.col, Msg: fmt.Sprintf("unexpected %s", t)}
+}
@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+)
+
+var testGroups = map[string][]string{
+	"ogerpon": {"オーガポン みどりのめんex", "オーガポン いどのめんex"},
+}
+
+func TestCompile(t *testing.T) {
+	for _, src := range []string{
+		`count("ドラパルトex") >= 2`,
+		`2 <= count("ドラパルトex")`,
+		`count("a") >= 2 && any("b", "c") || none("d")`,
+		`!(count("a") == 0) && kinds(ogerpon) >= 1`,
+		`sum("a", ogerpon) > 3 && all("a\"b")`,
+		`!!any("a")`,
+	} {
+		if _, err := Compile(src, testGroups); err != nil {
+			t.Errorf("Compile(%q): %v", src, err)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, tt := range []struct {
+		src    string
+		column int
+	}{
+		{`count("ドラパルトex") >= `, 21},
+		{`count("ドラパルトex") >= 2 &&`, 25},
+		{`count("ドラパルトex" >= 2`, 21},
+		{`count("ドラパルトex) >= 2`, 7},
+		{`count("ドラパルトex") >= 2 # 1`, 23},
+		{`count("ドラパルトex")`, 1},
+		{`count("ドラパルトex") >= "a"`, 21},
+		{`any("ドラパルトex") >= 2`, 1},
+		{`!count("a")`, 2},
+		{`count("a") && any("b")`, 1},
+		{`any("a") && count("b")`, 13},
+		{`count("ドラパルトex", "a") >= 2`, 1},
+		{`count(ogerpon) >= 2`, 7},
+		{`any(unknown)`, 5},
+		{`any(1)`, 5},
+		{`ogerpon`, 1},
+		{`total("a") >= 2`, 1},
+		{`any("a") any("b")`, 10},
+		{`(any("a")`, 10},
+		{`any()`, 1},
+	} {
+		_, err := Compile(tt.src, testGroups)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Compile(%q) = %v, want a syntax error", tt.src, err)
+			continue
+		}
+		if syntaxErr.Column != tt.column {
+			t.Errorf("Compile(%q): %v, want column %d", tt.src, err, tt.column)
+		}
+	}
+}
+
+func TestEval(t *testing.T) {
+	deck := map[string]int{
+		"a":              4,
+		"b":              2,
+		"オーガポン みどりのめんex": 3,
+	}
+
+	for _, tt := range []struct {
+		src  string
+		want bool
+	}{
+		{`count("a") == 4`, true},
+		{`count("a") != 4`, false},
+		{`count("a") > 4`, false},
+		{`count("a") >= 4`, true},
+		{`count("c") < 1`, true},
+		{`count("c") <= 0`, true},
+		{`3 < count("a")`, true},
+		{`sum("a", "b") == 6`, true},
+		{`sum(ogerpon) == 3`, true},
+		{`kinds("a", "b", "c") == 2`, true},
+		{`any("c", "b")`, true},
+		{`all("a", "b", "c")`, false},
+		{`none("c")`, true},
+		{`any(ogerpon)`, true},
+		{`all(ogerpon)`, false},
+
+		// "&&" binds tighter than "||", and "!" tighter than both.
+		{`any("a") || any("c") && any("c")`, true},
+		{`(any("a") || any("c")) && any("c")`, false},
+		{`any("c") && any("c") || any("a")`, true},
+		{`!any("a") || any("b")`, true},
+		{`!(any("a") || any("b"))`, false},
+		{`!any("c") && any("a")`, true},
+		{`!!any("a")`, true},
+		{`!count("a") == 4`, false},
+	} {
+		c, err := Compile(tt.src, testGroups)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.src, err)
+			continue
+		}
+		if got := c.Eval(deck); got != tt.want {
+			t.Errorf("Eval(%q) = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestMargin(t *testing.T) {
+	deck := map[string]int{"a": 4, "b": 2}
+
+	for _, tt := range []struct {
+		src  string
+		want int
+	}{
+		{`count("a") >= 2`, 2},
+		{`count("a") > 2`, 1},
+		{`2 <= count("a")`, 2},
+		{`count("a") == 4`, 0},
+		{`count("a") >= 2 && count("b") >= 1`, 3},
+		// Only the operands of "||" that are true count.
+		{`count("a") >= 2 || count("b") >= 3`, 2},
+		{`count("a") >= 2 || count("b") >= 1`, 3},
+		// Comparisons under "!" add nothing.
+		{`!(count("a") >= 5) && count("b") >= 1`, 1},
+		{`!(count("a") < 2)`, 0},
+	} {
+		c, err := Compile(tt.src, testGroups)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.src, err)
+			continue
+		}
+		if got := c.Margin(deck); got != tt.want {
+			t.Errorf("Margin(%q) = %d, want %d", tt.src, got, tt.want)
+		}
+	}
+}
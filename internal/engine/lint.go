@@ -0,0 +1,291 @@
+package engine
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Severity tells whether a lint finding is an error or a warning.
+type Severity int
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Finding is a problem the linter found in a rule. Rule is empty for
+// problems of the rule set as a whole.
+type Finding struct {
+	Environment string
+	Rule        string
+	Check       string
+	Severity    Severity
+	Msg         string
+}
+
+func (f *Finding) String() string {
+	if f.Rule == "" {
+		return fmt.Sprintf("%s: %s: %s (%s)", f.Environment, f.Severity, f.Msg, f.Check)
+	}
+	return fmt.Sprintf("%s: rule %q: %s: %s (%s)", f.Environment, f.Rule, f.Severity, f.Msg, f.Check)
+}
+
+// maxAssignments bounds the number of card count combinations the linter
+// tries for a single condition or pair of conditions.
+const maxAssignments = 1 << 20
+
+// Lint checks the rules of rs for likely mistakes:
+//
+//   - title: the title, or a part of a title joined with " & ", is one
+//     character away from a card the rule refers to, e.g. "メガドラミドロexx".
+//     A prefix such as the "毒" of "毒トドロクツキ" is not reported.
+//   - main-card: a main card does not appear in the rule's condition.
+//   - unsatisfiable: no deck can satisfy the condition.
+//   - variant: no deck satisfies both the condition of a variant and the
+//     condition of its rule, so the variant never applies.
+//   - overlap: two rules match exactly the same decks, so they always fire
+//     together.
+//   - group: a card group is used by no condition and no card list.
+//
+// Conditions are checked by trying every combination of the card counts at
+// which one of their comparisons can change its result.
+func Lint(rs *RuleSet) []*Finding {
+	var findings []*Finding
+	report := func(rule *Rule, check string, severity Severity, format string, args ...any) {
+		findings = append(findings, &Finding{
+			Environment: rs.Environment,
+			Rule:        rule.Title,
+			Check:       check,
+			Severity:    severity,
+			Msg:         fmt.Sprintf(format, args...),
+		})
+	}
+
+	satisfiable := make(map[*Rule]bool)
+	for _, rule := range rs.Rules {
+		cards := rule.cond.Cards()
+
+		if card, ok := similarCard(rule.Title, append(cards, rule.mainCards...)); ok {
+			report(rule, "title", SeverityError, "title differs from card %q by one character", card)
+		}
+
+		for _, card := range rule.mainCards {
+			if !slices.Contains(cards, card) {
+				report(rule, "main-card", SeverityWarning, "main card %q does not appear in the condition", card)
+			}
+		}
+
+		sat, ok := rule.cond.satisfiable()
+		if ok && !sat {
+			report(rule, "unsatisfiable", SeverityError, "condition can never be satisfied")
+		}
+		satisfiable[rule] = !ok || sat
+
+		for _, variant := range rule.Variants {
+			for _, card := range variant.mainCards {
+				if !slices.Contains(cards, card) && !slices.Contains(variant.cond.Cards(), card) {
+					report(rule, "main-card", SeverityWarning, "main card %q of variant %q does not appear in the conditions", card, variant.SubTitle)
+				}
+			}
+
+			if sat, ok := bothSatisfiable(rule.cond, variant.cond); ok && !sat {
+				report(rule, "variant", SeverityError, "variant %q can never match when the rule does", variant.SubTitle)
+			}
+		}
+	}
+
+	for i, a := range rs.Rules {
+		for _, b := range rs.Rules[i+1:] {
+			if !satisfiable[a] || !satisfiable[b] || !shareCards(a.cond, b.cond) {
+				continue
+			}
+
+			if equivalent(a.cond, b.cond) {
+				report(b, "overlap", SeverityError, "condition matches exactly the same decks as rule %q", a.Title)
+			}
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, rule := range rs.Rules {
+		for _, name := range rule.groups() {
+			used[name] = true
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(rs.Groups)) {
+		if !used[name] {
+			findings = append(findings, &Finding{
+				Environment: rs.Environment,
+				Check:       "group",
+				Severity:    SeverityError,
+				Msg:         fmt.Sprintf("group %s is never used", name),
+			})
+		}
+	}
+
+	return findings
+}
+
+// groups returns the names of the groups the rule and its variants refer to,
+// in their conditions or card lists.
+func (r *Rule) groups() []string {
+	names := r.cond.groups()
+	lists := [][]string{r.MainCards}
+	for _, variant := range r.Variants {
+		names = append(names, variant.cond.groups()...)
+		lists = append(lists, variant.MainCards, variant.SubCards)
+	}
+
+	for _, list := range lists {
+		for _, card := range list {
+			if groupName.MatchString(card) {
+				names = append(names, card)
+			}
+		}
+	}
+
+	return names
+}
+
+// similarCard returns the card that title, or a part of title, differs from
+// by exactly one character.
+func similarCard(title string, cards []string) (string, bool) {
+	parts := []string{title}
+	if split := strings.Split(title, " & "); len(split) > 1 {
+		parts = append(parts, split...)
+	}
+
+	for _, part := range parts {
+		if slices.Contains(cards, part) {
+			continue
+		}
+
+		for _, card := range cards {
+			if editDistance(part, card) == 1 && !strings.HasSuffix(part, card) {
+				return card, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func shareCards(a, b *Condition) bool {
+	bc := b.Cards()
+	for _, card := range a.Cards() {
+		if slices.Contains(bc, card) {
+			return true
+		}
+	}
+	return false
+}
+
+// satisfiable reports whether some deck satisfies the condition. ok is false
+// if there were too many combinations to try.
+func (c *Condition) satisfiable() (sat bool, ok bool) {
+	found := false
+	ok = enumerate(c.points(nil), func(cardlist map[string]int) bool {
+		found = c.Eval(cardlist)
+		return !found
+	})
+
+	return found, ok || found
+}
+
+// bothSatisfiable reports whether some deck satisfies both a and b. ok is
+// false if there were too many combinations to try.
+func bothSatisfiable(a, b *Condition) (sat bool, ok bool) {
+	found := false
+	ok = enumerate(b.points(a.points(nil)), func(cardlist map[string]int) bool {
+		found = a.Eval(cardlist) && b.Eval(cardlist)
+		return !found
+	})
+
+	return found, ok || found
+}
+
+// equivalent reports whether a and b give the same result for every deck.
+// It returns false if there were too many combinations to try.
+func equivalent(a, b *Condition) bool {
+	same := true
+	ok := enumerate(b.points(a.points(nil)), func(cardlist map[string]int) bool {
+		same = a.Eval(cardlist) == b.Eval(cardlist)
+		return same
+	})
+
+	return ok && same
+}
+
+// enumerate calls fn with every combination of the given counts of each card
+// until fn returns false. It returns false if there are more than
+// maxAssignments combinations.
+func enumerate(points map[string][]int, fn func(cardlist map[string]int) bool) bool {
+	cards := make([]string, 0, len(points))
+	total := 1
+	for card, counts := range points {
+		cards = append(cards, card)
+		total *= len(counts)
+		if total > maxAssignments {
+			return false
+		}
+	}
+	sort.Strings(cards)
+
+	idx := make([]int, len(cards))
+	cardlist := make(map[string]int, len(cards))
+	for {
+		for i, card := range cards {
+			cardlist[card] = points[card][idx[i]]
+		}
+
+		if !fn(cardlist) {
+			return true
+		}
+
+		i := 0
+		for ; i < len(cards); i++ {
+			idx[i]++
+			if idx[i] < len(points[cards[i]]) {
+				break
+			}
+			idx[i] = 0
+		}
+		if i == len(cards) {
+			return true
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	for _, tt := range []struct {
+		check string
+		rules string
+	}{
+		{"title", `
+rules:
+  - title: "メガドラミドロexx"
+    when: count("メガドラミドロex") >= 2
+    main_cards: ["メガドラミドロex"]
+`},
+		{"title", `
+rules:
+  - title: "メガガルーラex & メガアブソルexx"
+    when: count("メガガルーラex") >= 2 && count("メガアブソルex") >= 2
+    main_cards: ["メガガルーラex", "メガアブソルex"]
+`},
+		{"main-card", `
+rules:
+  - title: "a"
+    when: count("a") >= 2
+    main_cards: ["a", "b"]
+`},
+		{"main-card", `
+rules:
+  - title: "a"
+    when: count("a") >= 2
+    main_cards: ["a"]
+    variants:
+      - sub_title: "b"
+        when: count("b") >= 1
+        main_cards: ["a", "c"]
+        sub_cards: ["b"]
+`},
+		{"unsatisfiable", `
+rules:
+  - title: "a"
+    when: count("a") == 4 && count("a") == 0
+    main_cards: ["a"]
+`},
+		{"variant", `
+rules:
+  - title: "a"
+    when: count("a") >= 2 && none("b")
+    main_cards: ["a"]
+    variants:
+      - sub_title: "b"
+        when: count("b") >= 1
+        sub_cards: ["b"]
+`},
+		{"overlap", `
+rules:
+  - title: "a"
+    when: count("a") >= 2
+    main_cards: ["a"]
+  - title: "a bullet"
+    when: 2 <= count("a")
+    main_cards: ["a"]
+`},
+		{"group", `
+groups:
+  unused: ["b"]
+rules:
+  - title: "a"
+    when: count("a") >= 2
+    main_cards: ["a"]
+`},
+	} {
+		ruleSets, err := Load(ruleFS(map[string]string{"x.yaml": header("x", 1) + tt.rules}))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.check, err)
+		}
+
+		findings := Lint(ruleSets["x"])
+		if len(findings) != 1 || findings[0].Check != tt.check {
+			t.Errorf("%s: findings = %v, want one %s finding", tt.check, findings, tt.check)
+		}
+	}
+}
+
+func TestLintClean(t *testing.T) {
+	ruleSets, err := Load(ruleFS(map[string]string{"x.yaml": header("x", 1) + `
+groups:
+  poison: ["モモワロウ", "アラブルタケ"]
+rules:
+  - title: "トドロクツキex"
+    when: count("トドロクツキex") >= 2
+    main_cards: ["トドロクツキex"]
+    variants:
+      - title: "毒トドロクツキ"
+        sub_title: "モモワロウ/アラブルタケ"
+        when: all(poison)
+        main_cards: ["トドロクツキex", poison]
+        sub_cards: [poison]
+  - title: "ドラパルトex"
+    when: count("ドラパルトex") >= 2 && count("トドロクツキex") <= 1
+    main_cards: ["ドラパルトex"]
+`}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if findings := Lint(ruleSets["x"]); len(findings) != 0 {
+		t.Errorf("findings = %v, want none", findings)
+	}
+}
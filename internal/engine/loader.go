@@ -0,0 +1,416 @@
+package engine
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ruleFile is the content of a rule file. A file either lists all rules of
+// its environment under rules, or extends the environment of another file
+// and lists the rules it adds, replaces and removes. Groups of an extending
+// file are added to, or replace, the groups of the environment it extends.
+// The metadata of the environment, from name to start, is never inherited.
+type ruleFile struct {
+	Environment     string              `yaml:"environment"`
+	Extends         string              `yaml:"extends"`
+	Name            string              `yaml:"name"`
+	Set             string              `yaml:"set"`
+	RegulationMarks []string            `yaml:"regulation_marks"`
+	Start           Date                `yaml:"start"`
+	Groups          map[string][]string `yaml:"groups"`
+	Rules           []*Rule             `yaml:"rules"`
+	Add             []*ruleOverride     `yaml:"add"`
+	Replace         []*ruleOverride     `yaml:"replace"`
+	Remove          []string            `yaml:"remove"`
+
+	name string
+}
+
+// ruleOverride is a rule added or replaced by an extending file. An added
+// rule is appended unless before or after names the rule it is placed next
+// to; a replaced rule keeps its place unless one of them is set.
+type ruleOverride struct {
+	Rule   `yaml:",inline"`
+	Before string `yaml:"before"`
+	After  string `yaml:"after"`
+}
+
+// Load reads every *.yaml file at the root of fsys as the rule set of one
+// environment, resolves the environments they extend and validates them.
+// The returned map is keyed by environment.
+func Load(fsys fs.FS) (map[string]*RuleSet, error) {
+	names, err := fs.Glob(fsys, "*.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		return nil, errors.New("no rule files found")
+	}
+
+	files := make(map[string]*ruleFile)
+	for _, name := range names {
+		f, err := loadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		if _, ok := files[f.Environment]; ok {
+			return nil, fmt.Errorf("%s: environment %q is defined more than once", name, f.Environment)
+		}
+
+		for _, other := range files {
+			if other.Start.Equal(f.Start.Time) {
+				return nil, fmt.Errorf("%s: environment %q starts on the same day as %q", name, f.Environment, other.Environment)
+			}
+		}
+
+		files[f.Environment] = f
+	}
+
+	aceSpecs, err := LoadAceSpecs(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", aceSpecFile, err)
+	}
+
+	ruleSets := make(map[string]*RuleSet)
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if _, err := resolve(name, files, ruleSets, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, rs := range ruleSets {
+		rs.aceSpecs = aceSpecs
+
+		if err := rs.validate(); err != nil {
+			return nil, fmt.Errorf("%s.yaml: %w", rs.Environment, err)
+		}
+
+		version, err := rs.hash()
+		if err != nil {
+			return nil, fmt.Errorf("%s.yaml: %w", rs.Environment, err)
+		}
+		rs.Version = version
+	}
+
+	return ruleSets, nil
+}
+
+func loadFile(fsys fs.FS, name string) (*ruleFile, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	f := &ruleFile{name: name}
+	if err := dec.Decode(f); err != nil {
+		return nil, err
+	}
+
+	if want := strings.TrimSuffix(path.Base(name), ".yaml"); f.Environment != want {
+		return nil, fmt.Errorf("environment %q does not match the file name", f.Environment)
+	}
+
+	switch {
+	case f.Name == "":
+		return nil, errors.New("name is required")
+	case f.Set == "":
+		return nil, errors.New("set is required")
+	case len(f.RegulationMarks) == 0:
+		return nil, errors.New("regulation_marks is required")
+	case f.Start.IsZero():
+		return nil, errors.New("start is required")
+	}
+
+	if f.Extends == "" && (f.Add != nil || f.Replace != nil || f.Remove != nil) {
+		return nil, errors.New("add, replace and remove require extends")
+	}
+
+	if f.Extends != "" && f.Rules != nil {
+		return nil, errors.New("rules cannot be used with extends, use add, replace and remove")
+	}
+
+	return f, nil
+}
+
+// resolve returns the rule set of env with the environments it extends
+// applied. Resolved rule sets are stored in ruleSets; seen holds the
+// environments being resolved to detect cycles.
+func resolve(env string, files map[string]*ruleFile, ruleSets map[string]*RuleSet, seen []string) (*RuleSet, error) {
+	if rs, ok := ruleSets[env]; ok {
+		return rs, nil
+	}
+
+	f := files[env]
+
+	if slices.Contains(seen, env) {
+		return nil, fmt.Errorf("%s: environments extend each other: %s", f.name, strings.Join(append(seen, env), " -> "))
+	}
+
+	rs := &RuleSet{
+		Environment:     env,
+		Extends:         f.Extends,
+		Name:            f.Name,
+		Set:             f.Set,
+		RegulationMarks: f.RegulationMarks,
+		Start:           f.Start,
+		Groups:          make(map[string][]string),
+	}
+
+	if f.Extends == "" {
+		rs.Rules = f.Rules
+		for _, rule := range rs.Rules {
+			if rule.ID == "" {
+				rule.ID = rule.Title
+			}
+		}
+	} else {
+		if _, ok := files[f.Extends]; !ok {
+			return nil, fmt.Errorf("%s: extends unknown environment %q", f.name, f.Extends)
+		}
+
+		base, err := resolve(f.Extends, files, ruleSets, append(seen, env))
+		if err != nil {
+			return nil, err
+		}
+
+		maps.Copy(rs.Groups, base.Groups)
+		for _, rule := range base.Rules {
+			rs.Rules = append(rs.Rules, rule.clone())
+		}
+
+		if err := rs.override(f); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+	}
+
+	maps.Copy(rs.Groups, f.Groups)
+	ruleSets[env] = rs
+
+	return rs, nil
+}
+
+// hash returns the first 12 hex digits of the SHA-256 of the rule set
+// encoded as YAML and the ACE SPEC list.
+func (rs *RuleSet) hash() (string, error) {
+	data, err := yaml.Marshal(rs)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write(data)
+	for _, card := range rs.aceSpecs {
+		h.Write([]byte(card + "\n"))
+	}
+
+	sum := h.Sum(nil)
+	return hex.EncodeToString(sum[:])[:12], nil
+}
+
+// override applies the removed, replaced and added rules of f, in that order.
+func (rs *RuleSet) override(f *ruleFile) error {
+	for _, id := range f.Remove {
+		i := rs.index(id)
+		if i < 0 {
+			return fmt.Errorf("remove: unknown rule %q", id)
+		}
+		rs.Rules = slices.Delete(rs.Rules, i, i+1)
+	}
+
+	for _, o := range f.Replace {
+		rule := o.rule()
+		i := rs.index(rule.ID)
+		if i < 0 {
+			return fmt.Errorf("replace: unknown rule %q", rule.ID)
+		}
+
+		if o.Before == "" && o.After == "" {
+			rs.Rules[i] = rule
+			continue
+		}
+
+		rs.Rules = slices.Delete(rs.Rules, i, i+1)
+		if err := rs.insert(o, rule); err != nil {
+			return fmt.Errorf("replace: rule %q: %w", rule.ID, err)
+		}
+	}
+
+	for _, o := range f.Add {
+		rule := o.rule()
+		if rs.index(rule.ID) >= 0 {
+			return fmt.Errorf("add: rule %q already exists, use replace", rule.ID)
+		}
+
+		if err := rs.insert(o, rule); err != nil {
+			return fmt.Errorf("add: rule %q: %w", rule.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (o *ruleOverride) rule() *Rule {
+	rule := o.Rule
+	if rule.ID == "" {
+		rule.ID = rule.Title
+	}
+
+	return &rule
+}
+
+// insert places rule before or after the rule named by o, or at the end.
+func (rs *RuleSet) insert(o *ruleOverride, rule *Rule) error {
+	if o.Before != "" && o.After != "" {
+		return errors.New("before and after cannot be used together")
+	}
+
+	i := len(rs.Rules)
+	switch {
+	case o.Before != "":
+		if i = rs.index(o.Before); i < 0 {
+			return fmt.Errorf("before: unknown rule %q", o.Before)
+		}
+	case o.After != "":
+		if i = rs.index(o.After); i < 0 {
+			return fmt.Errorf("after: unknown rule %q", o.After)
+		}
+		i++
+	}
+
+	rs.Rules = slices.Insert(rs.Rules, i, rule)
+
+	return nil
+}
+
+func (rs *RuleSet) index(id string) int {
+	return slices.IndexFunc(rs.Rules, func(rule *Rule) bool {
+		return rule.ID == id
+	})
+}
+
+var groupName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (rs *RuleSet) validate() error {
+	for name, cards := range rs.Groups {
+		if name == aceSpecGroup {
+			return fmt.Errorf("group %s: name is reserved for the ACE SPEC cards", name)
+		}
+
+		if !groupName.MatchString(name) {
+			return fmt.Errorf("group %q: name must consist of ASCII letters, digits and underscores", name)
+		}
+
+		if len(cards) == 0 {
+			return fmt.Errorf("group %s: must not be empty", name)
+		}
+	}
+
+	groups := maps.Clone(rs.Groups)
+	groups[aceSpecGroup] = aceSpecCards(rs.aceSpecs)
+
+	ids := make(map[string]bool)
+	titles := make(map[string]bool)
+	for i, rule := range rs.Rules {
+		if rule.Title == "" {
+			return fmt.Errorf("rule #%d: missing title", i+1)
+		}
+
+		if ids[rule.ID] {
+			return fmt.Errorf("rule %q: id is used more than once", rule.ID)
+		}
+		ids[rule.ID] = true
+
+		if titles[rule.Title] {
+			return fmt.Errorf("rule %q: title is used more than once", rule.Title)
+		}
+		titles[rule.Title] = true
+
+		cond, err := Compile(rule.When, groups)
+		if err != nil {
+			return fmt.Errorf("rule %q: when: %w", rule.Title, err)
+		}
+		rule.cond = cond
+
+		if len(rule.MainCards) == 0 {
+			return fmt.Errorf("rule %q: main_cards must not be empty", rule.Title)
+		}
+		rule.mainCards = expandCards(rule.MainCards, groups)
+
+		if err := rule.validateVariants(groups, titles); err != nil {
+			return fmt.Errorf("rule %q: %w", rule.Title, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Rule) validateVariants(groups map[string][]string, titles map[string]bool) error {
+	subTitles := make(map[string]bool)
+	for i, variant := range r.Variants {
+		if variant.SubTitle == "" {
+			return fmt.Errorf("variant #%d: missing sub_title", i+1)
+		}
+
+		if subTitles[variant.SubTitle] {
+			return fmt.Errorf("variant %q: sub_title is used more than once", variant.SubTitle)
+		}
+		subTitles[variant.SubTitle] = true
+
+		if variant.Title != "" {
+			if titles[variant.Title] {
+				return fmt.Errorf("variant %q: title %q is used more than once", variant.SubTitle, variant.Title)
+			}
+			titles[variant.Title] = true
+		}
+
+		cond, err := Compile(variant.When, groups)
+		if err != nil {
+			return fmt.Errorf("variant %q: when: %w", variant.SubTitle, err)
+		}
+		variant.cond = cond
+
+		if len(variant.SubCards) == 0 {
+			return fmt.Errorf("variant %q: sub_cards must not be empty", variant.SubTitle)
+		}
+		variant.subCards = expandCards(variant.SubCards, groups)
+		variant.mainCards = expandCards(variant.MainCards, groups)
+	}
+
+	return nil
+}
+
+// expandCards replaces the entries of cards that name a group with the cards
+// of the group, dropping cards listed more than once.
+func expandCards(cards []string, groups map[string][]string) []string {
+	var expanded []string
+	for _, card := range cards {
+		members, ok := groups[card]
+		if !ok {
+			members = []string{card}
+		}
+
+		for _, member := range members {
+			if !slices.Contains(expanded, member) {
+				expanded = append(expanded, member)
+			}
+		}
+	}
+
+	return expanded
+}
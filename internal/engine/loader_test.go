@@ -0,0 +1,227 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// ruleFS returns an in-memory rules directory holding the given files.
+func ruleFS(files map[string]string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for name, data := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(data)}
+	}
+	return fsys
+}
+
+// header returns the metadata of a rule file of env, starting on day of
+// January 2026.
+func header(env string, day int) string {
+	return fmt.Sprintf("environment: %s\nname: %[1]s\nset: %[1]s\nregulation_marks: [\"H\"]\nstart: 2026-01-%02d\n", env, day)
+}
+
+func TestGroupCardLists(t *testing.T) {
+	ruleSets, err := Load(ruleFS(map[string]string{
+		"a.yaml": header("a", 1) + `
+groups:
+  masks: ["b", "c"]
+rules:
+  - title: "a"
+    when: count("a") >= 2
+    main_cards: ["a", masks, "c"]
+    variants:
+      - sub_title: "masks"
+        when: any(masks)
+        sub_cards: [masks]
+`,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := ruleSets["a"].Classify([]*Card{{Name: "a", Count: 2}, {Name: "c", Count: 1}, {Name: "b", Count: 1}})
+	var main, sub []string
+	for _, card := range result.Primary.MainCards {
+		main = append(main, card.Name)
+	}
+	for _, card := range result.Primary.SubCards {
+		sub = append(sub, card.Name)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(main, want) {
+		t.Errorf("main cards = %v, want %v", main, want)
+	}
+	if want := []string{"b", "c"}; !reflect.DeepEqual(sub, want) {
+		t.Errorf("sub cards = %v, want %v", sub, want)
+	}
+}
+
+const baseRules = `
+rules:
+  - title: "a"
+    when: count("a") >= 2
+    main_cards: ["a"]
+  - id: "b"
+    title: "b old"
+    when: count("b") >= 2
+    main_cards: ["b"]
+  - title: "c"
+    when: count("c") >= 2
+    main_cards: ["c"]
+`
+
+func titles(rs *RuleSet) []string {
+	var titles []string
+	for _, rule := range rs.Rules {
+		titles = append(titles, rule.Title)
+	}
+	return titles
+}
+
+func TestExtends(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		override string
+		want     []string
+	}{
+		{"add", `
+add:
+  - title: "d"
+    when: count("d") >= 2
+    main_cards: ["d"]
+`, []string{"a", "b old", "c", "d"}},
+		{"add before", `
+add:
+  - title: "d"
+    when: count("d") >= 2
+    main_cards: ["d"]
+    before: "a"
+`, []string{"d", "a", "b old", "c"}},
+		{"add after", `
+add:
+  - title: "d"
+    when: count("d") >= 2
+    main_cards: ["d"]
+    after: "b"
+`, []string{"a", "b old", "d", "c"}},
+		{"replace by id", `
+replace:
+  - id: "b"
+    title: "b new"
+    when: count("b") >= 3
+    main_cards: ["b"]
+`, []string{"a", "b new", "c"}},
+		{"replace and move", `
+replace:
+  - title: "c"
+    when: count("c") >= 3
+    main_cards: ["c"]
+    before: "a"
+`, []string{"c", "a", "b old"}},
+		{"remove by id", `
+remove: ["b"]
+`, []string{"a", "c"}},
+		{"remove, replace and add", `
+remove: ["a"]
+replace:
+  - title: "c"
+    when: count("c") >= 3
+    main_cards: ["c"]
+add:
+  - title: "a"
+    when: count("a") >= 3
+    main_cards: ["a"]
+    after: "c"
+`, []string{"b old", "c", "a"}},
+	} {
+		ruleSets, err := Load(ruleFS(map[string]string{
+			"base.yaml": header("base", 1) + baseRules,
+			"ext.yaml":  header("ext", 2) + "extends: base\n" + tt.override,
+		}))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+
+		if got := titles(ruleSets["ext"]); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: rules = %v, want %v", tt.name, got, tt.want)
+		}
+		if got, want := titles(ruleSets["base"]), []string{"a", "b old", "c"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: base rules = %v, want %v", tt.name, got, want)
+		}
+	}
+}
+
+func TestExtendsErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		override string
+		want     string
+	}{
+		{"remove unknown", `
+remove: ["x"]
+`, `ext.yaml: remove: unknown rule "x"`},
+		{"replace unknown", `
+replace:
+  - title: "x"
+    when: count("x") >= 2
+    main_cards: ["x"]
+`, `ext.yaml: replace: unknown rule "x"`},
+		{"add existing", `
+add:
+  - title: "a"
+    when: count("a") >= 2
+    main_cards: ["a"]
+`, `ext.yaml: add: rule "a" already exists, use replace`},
+		{"before unknown", `
+add:
+  - title: "d"
+    when: count("d") >= 2
+    main_cards: ["d"]
+    before: "x"
+`, `ext.yaml: add: rule "d": before: unknown rule "x"`},
+		{"after unknown", `
+add:
+  - title: "d"
+    when: count("d") >= 2
+    main_cards: ["d"]
+    after: "x"
+`, `ext.yaml: add: rule "d": after: unknown rule "x"`},
+		{"before and after", `
+add:
+  - title: "d"
+    when: count("d") >= 2
+    main_cards: ["d"]
+    before: "a"
+    after: "c"
+`, `ext.yaml: add: rule "d": before and after cannot be used together`},
+		{"rules with extends", baseRules, `ext.yaml: rules cannot be used with extends, use add, replace and remove`},
+	} {
+		_, err := Load(ruleFS(map[string]string{
+			"base.yaml": header("base", 1) + baseRules,
+			"ext.yaml":  header("ext", 2) + "extends: base\n" + tt.override,
+		}))
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: error = %v, want %s", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestExtendsCycle(t *testing.T) {
+	_, err := Load(ruleFS(map[string]string{
+		"a.yaml": header("a", 1) + "extends: c\n",
+		"b.yaml": header("b", 2) + "extends: a\n",
+		"c.yaml": header("c", 3) + "extends: b\n",
+	}))
+	if want := "a.yaml: environments extend each other: a -> c -> b -> a"; err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %s", err, want)
+	}
+
+	_, err = Load(ruleFS(map[string]string{
+		"a.yaml": header("a", 1) + "extends: x\n",
+	}))
+	if want := `a.yaml: extends unknown environment "x"`; err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %s", err, want)
+	}
+}
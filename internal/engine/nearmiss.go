@@ -0,0 +1,227 @@
+package engine
+
+import (
+	"cmp"
+	"slices"
+)
+
+// MissingCondition is a part of a rule condition a deck does not satisfy.
+// Off is the number of copies, or of different cards for kinds, the deck is
+// away from satisfying it.
+type MissingCondition struct {
+	Expr      string `json:"expr"`
+	Actual    *int   `json:"actual,omitempty"`
+	Op        string `json:"op,omitempty"`
+	Threshold *int   `json:"threshold,omitempty"`
+	Off       int    `json:"off"`
+}
+
+// NearMiss is a rule a deck did not match, with the parts of its condition
+// that failed. Distance is the total number of copies the deck would need
+// to add or remove to match it.
+type NearMiss struct {
+	ID       string              `json:"id"`
+	Title    string              `json:"title"`
+	Distance int                 `json:"distance"`
+	Missing  []*MissingCondition `json:"missing"`
+}
+
+// NearMisses lists the rules of an environment a deck came closest to.
+type NearMisses struct {
+	Environment string      `json:"environment"`
+	NearMisses  []*NearMiss `json:"near_misses"`
+}
+
+// NearMisses returns up to limit rules the deck does not match but is at
+// most maxDistance copies away from, closest first and in rule order among
+// rules at the same distance.
+//
+// The distance of a comparison is how far the count is from the threshold,
+// e.g. 1 for count("a") >= 2 with one copy. The distance of "&&" is the sum
+// of the distances of its operands, the distance of "||" is the smallest
+// one. For a "||" only the missing conditions of its closest operand are
+// reported.
+func (rs *RuleSet) NearMisses(deck []*Card, maxDistance, limit int) *NearMisses {
+	cardlist := countCards(deck)
+
+	nearMisses := []*NearMiss{}
+	for _, rule := range rs.Rules {
+		if rule.cond.Eval(cardlist) {
+			continue
+		}
+
+		d, missing := distance(rule.cond.root, true, cardlist)
+		if d > maxDistance {
+			continue
+		}
+
+		nearMisses = append(nearMisses, &NearMiss{
+			ID:       rule.ID,
+			Title:    rule.Title,
+			Distance: d,
+			Missing:  missing,
+		})
+	}
+
+	slices.SortStableFunc(nearMisses, func(a, b *NearMiss) int {
+		return cmp.Compare(a.Distance, b.Distance)
+	})
+
+	if len(nearMisses) > limit {
+		nearMisses = nearMisses[:limit]
+	}
+
+	return &NearMisses{
+		Environment: rs.Environment,
+		NearMisses:  nearMisses,
+	}
+}
+
+// distance returns how many copies the deck is away from n evaluating to
+// want, and the failing conditions that make up the distance.
+func distance(n node, want bool, cardlist map[string]int) (int, []*MissingCondition) {
+	if evalBool(n, cardlist) == want {
+		return 0, nil
+	}
+
+	switch n := n.(type) {
+	case *notExpr:
+		return distance(n.x, !want, cardlist)
+
+	case *binaryExpr:
+		// "a && b" is false when either operand is; "a || b" is true when
+		// either operand is.
+		if n.op == tokAnd && want || n.op == tokOr && !want {
+			total := 0
+			var missing []*MissingCondition
+			for _, operand := range operands(n, n.op) {
+				d, m := distance(operand, want, cardlist)
+				total += d
+				missing = append(missing, m...)
+			}
+			return total, missing
+		}
+
+		if n.op == tokAnd || n.op == tokOr {
+			best := -1
+			var missing []*MissingCondition
+			for _, operand := range operands(n, n.op) {
+				d, m := distance(operand, want, cardlist)
+				if best < 0 || d < best {
+					best, missing = d, m
+				}
+			}
+			return best, missing
+		}
+
+		return compareDistance(n, want, cardlist)
+
+	case *call:
+		off := callDistance(n, want, cardlist)
+		return off, []*MissingCondition{{Expr: negate(n, want), Off: off}}
+	}
+
+	panic("unreachable")
+}
+
+func compareDistance(n *binaryExpr, want bool, cardlist map[string]int) (int, []*MissingCondition) {
+	op, count, limit, ok := bounds(n)
+	if !ok {
+		return 1, []*MissingCondition{{Expr: negate(n, want), Off: 1}}
+	}
+
+	if !want {
+		op = negateOp(op)
+	}
+
+	actual, threshold := evalInt(count, cardlist), limit.value
+
+	var off int
+	switch op {
+	case tokGe:
+		off = threshold - actual
+	case tokGt:
+		off = threshold + 1 - actual
+	case tokLe:
+		off = actual - threshold
+	case tokLt:
+		off = actual - threshold + 1
+	case tokEq:
+		off = max(actual-threshold, threshold-actual)
+	case tokNe:
+		off = 1
+	}
+
+	return off, []*MissingCondition{{
+		Expr:      negate(n, want),
+		Actual:    &actual,
+		Op:        operatorText(op),
+		Threshold: &threshold,
+		Off:       off,
+	}}
+}
+
+// callDistance returns how many copies have to be added or removed for a
+// call to any, all or none to evaluate to want. The call currently
+// evaluates to !want.
+func callDistance(n *call, want bool, cardlist map[string]int) int {
+	name := n.name
+	if !want {
+		// not any is none and not none is any
+		switch name {
+		case "any":
+			name = "none"
+		case "none":
+			name = "any"
+		}
+	}
+
+	switch name {
+	case "any":
+		return 1
+	case "none":
+		total := 0
+		for _, card := range n.cards {
+			total += cardlist[card]
+		}
+		return total
+	}
+
+	// all
+	if want {
+		return len(n.cards) - countKinds(n.cards, cardlist)
+	}
+
+	fewest := -1
+	for _, card := range n.cards {
+		if fewest < 0 || cardlist[card] < fewest {
+			fewest = cardlist[card]
+		}
+	}
+	return fewest
+}
+
+func negateOp(op tokenKind) tokenKind {
+	switch op {
+	case tokGe:
+		return tokLt
+	case tokGt:
+		return tokLe
+	case tokLe:
+		return tokGt
+	case tokLt:
+		return tokGe
+	case tokEq:
+		return tokNe
+	default:
+		return tokEq
+	}
+}
+
+// negate formats n, negated unless want is true.
+func negate(n node, want bool) string {
+	if want {
+		return format(n)
+	}
+	return "!" + formatOperand(n, tokNot)
+}
@@ -0,0 +1,83 @@
+package engine
+
+import "testing"
+
+func TestDistance(t *testing.T) {
+	for _, tt := range []struct {
+		src  string
+		deck map[string]int
+		want int
+	}{
+		{`count("a") >= 2`, nil, 2},
+		{`2 <= count("a")`, nil, 2},
+		{`count("a") > 2`, map[string]int{"a": 1}, 2},
+		{`count("a") <= 1`, map[string]int{"a": 3}, 2},
+		{`count("a") < 1`, map[string]int{"a": 3}, 3},
+		{`count("a") == 4`, map[string]int{"a": 1}, 3},
+		{`count("a") == 1`, map[string]int{"a": 4}, 3},
+		{`count("a") != 4`, map[string]int{"a": 4}, 1},
+		{`sum("a") >= count("b")`, map[string]int{"b": 1}, 1},
+
+		// "!" negates the operator of a comparison.
+		{`!(count("a") == 4)`, map[string]int{"a": 4}, 1},
+		{`!(count("a") != 4)`, map[string]int{"a": 1}, 3},
+		{`!(count("a") >= 2)`, map[string]int{"a": 4}, 3},
+		{`!(count("a") < 2)`, map[string]int{"a": 0}, 2},
+
+		// "&&" adds up its operands, "||" takes the closest one, and "!"
+		// swaps them.
+		{`count("a") >= 2 && count("b") >= 4`, map[string]int{"a": 1, "b": 1}, 4},
+		{`count("a") >= 2 || count("b") >= 4`, map[string]int{"a": 1, "b": 1}, 1},
+		{`count("a") >= 4 || count("b") >= 2 || any("c")`, map[string]int{"a": 1}, 1},
+		{`!(count("a") >= 1 && count("b") >= 1)`, map[string]int{"a": 3, "b": 1}, 1},
+		{`!(count("a") >= 1 || count("b") >= 1)`, map[string]int{"a": 3, "b": 1}, 4},
+
+		{`any("a", "b")`, nil, 1},
+		{`none("a", "b")`, map[string]int{"a": 2, "b": 1}, 3},
+		{`all("a", "b", "c")`, map[string]int{"a": 1}, 2},
+		{`!any("a", "b")`, map[string]int{"a": 2, "b": 1}, 3},
+		{`!none("a", "b")`, nil, 1},
+		{`!all("a", "b")`, map[string]int{"a": 2, "b": 3}, 2},
+		{`!all("a", "b") && count("c") >= 1`, map[string]int{"a": 2, "b": 3}, 3},
+
+		// kinds counts different cards, not copies.
+		{`kinds("a", "b", "c") >= 3`, map[string]int{"a": 4}, 2},
+		{`kinds("a", "b", "c") <= 1`, map[string]int{"a": 4, "b": 4, "c": 4}, 2},
+	} {
+		c, err := Compile(tt.src, nil)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.src, err)
+			continue
+		}
+
+		got, missing := distance(c.root, true, tt.deck)
+		if got != tt.want {
+			t.Errorf("distance(%q) = %d, want %d", tt.src, got, tt.want)
+		}
+
+		total := 0
+		for _, m := range missing {
+			total += m.Off
+		}
+		if total != got {
+			t.Errorf("distance(%q): missing conditions add up to %d, want %d", tt.src, total, got)
+		}
+	}
+}
+
+func TestDistanceMissing(t *testing.T) {
+	c, err := Compile(`!(count("a") == 4) || count("b") >= 3`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, missing := distance(c.root, true, map[string]int{"a": 4, "b": 1})
+	if len(missing) != 1 {
+		t.Fatalf("missing = %v, want one condition", missing)
+	}
+
+	m := missing[0]
+	if m.Expr != `!(count("a") == 4)` || m.Op != "!=" || *m.Actual != 4 || *m.Threshold != 4 || m.Off != 1 {
+		t.Errorf("missing = %+v", m)
+	}
+}
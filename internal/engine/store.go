@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Store holds the active rule sets loaded from a file system. Reloading
+// validates the new rule sets before swapping them in atomically, so callers
+// that took a snapshot keep using it until they are done.
+type Store struct {
+	fsys     fs.FS
+	ruleSets atomic.Pointer[map[string]*RuleSet]
+}
+
+// NewStore loads the rule sets in fsys.
+func NewStore(fsys fs.FS) (*Store, error) {
+	s := &Store{fsys: fsys}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// RuleSets returns a snapshot of the active rule sets keyed by environment.
+func (s *Store) RuleSets() map[string]*RuleSet {
+	return *s.ruleSets.Load()
+}
+
+// Environments returns the environments of the active rule sets, oldest
+// first.
+func (s *Store) Environments() []*Environment {
+	return Environments(s.RuleSets())
+}
+
+// RuleSetAt returns the rule set of the environment that was active on
+// date, given as YYYY-MM-DD. It falls back to the newest environment if date
+// is empty or before the oldest environment.
+func (s *Store) RuleSetAt(date string) (*RuleSet, error) {
+	ruleSets := s.RuleSets()
+	envs := Environments(ruleSets)
+	newest := ruleSets[envs[len(envs)-1].ID]
+
+	if date == "" {
+		return newest, nil
+	}
+
+	d, err := ParseDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	env := EnvironmentAt(envs, d)
+	if env == nil {
+		return newest, nil
+	}
+
+	return ruleSets[env.ID], nil
+}
+
+// Reload loads the rule sets again. If they fail to validate, or an
+// environment of the active rule sets is missing, the active rule sets are
+// kept and the error is returned.
+func (s *Store) Reload() error {
+	ruleSets, err := Load(s.fsys)
+	if err != nil {
+		return err
+	}
+
+	if current := s.ruleSets.Load(); current != nil {
+		for env := range *current {
+			if _, ok := ruleSets[env]; !ok {
+				return fmt.Errorf("environment %q is missing", env)
+			}
+		}
+	}
+
+	s.ruleSets.Store(&ruleSets)
+
+	return nil
+}
+
+// Watch polls the rule files every interval and reloads them when one of
+// them changes, until ctx is done. Failed reloads are logged.
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	last, _ := s.fingerprint()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		fp, err := s.fingerprint()
+		if err != nil {
+			log.Printf("failed to check rule files: %s\n", err)
+			continue
+		}
+
+		if fp == last {
+			continue
+		}
+		last = fp
+
+		if err := s.Reload(); err != nil {
+			log.Printf("failed to reload rules, keeping the previous rules: %s\n", err)
+			continue
+		}
+
+		log.Println("rules reloaded")
+	}
+}
+
+// fingerprint summarizes the names, sizes and modification times of the
+// rule files and the ACE SPEC list.
+func (s *Store) fingerprint() (string, error) {
+	names, err := fs.Glob(s.fsys, "*.yaml")
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, name := range append(names, aceSpecFile) {
+		info, err := fs.Stat(s.fsys, name)
+		if errors.Is(err, fs.ErrNotExist) && name == aceSpecFile {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(&sb, "%s:%d:%d;", name, info.Size(), info.ModTime().UnixNano())
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,91 @@
+// Package flight coalesces concurrent calls doing the same work.
+package flight
+
+import (
+	"context"
+	"sync"
+)
+
+type call[V any] struct {
+	done    chan struct{}
+	val     V
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// Group runs at most one call per key at a time. Callers asking for a key
+// while its call is running wait for that call and share its result.
+type Group[V any] struct {
+	mu    sync.Mutex
+	calls map[string]*call[V]
+}
+
+// Do runs fn for key, or waits for the call already running for key.
+// shared reports whether the result came from a call another caller
+// started.
+//
+// fn gets a context that is canceled once every caller waiting for it has
+// given up, so the work stops when nobody needs it anymore but is not cut
+// short when only the caller that started it leaves. A caller whose ctx is
+// done returns ctx.Err() without waiting further.
+func (g *Group[V]) Do(ctx context.Context, key string, fn func(ctx context.Context) (V, error)) (v V, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call[V])
+	}
+
+	if c, ok := g.calls[key]; ok {
+		c.waiters++
+		g.mu.Unlock()
+
+		v, err = g.wait(ctx, key, c)
+		return v, err, true
+	}
+
+	callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	c := &call[V]{
+		done:    make(chan struct{}),
+		waiters: 1,
+		cancel:  cancel,
+	}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	go func() {
+		c.val, c.err = fn(callCtx)
+		cancel()
+
+		g.mu.Lock()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+
+		close(c.done)
+	}()
+
+	v, err = g.wait(ctx, key, c)
+	return v, err, false
+}
+
+func (g *Group[V]) wait(ctx context.Context, key string, c *call[V]) (V, error) {
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+	}
+
+	g.mu.Lock()
+	c.waiters--
+	if c.waiters == 0 {
+		c.cancel()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+	}
+	g.mu.Unlock()
+
+	var zero V
+	return zero, ctx.Err()
+}
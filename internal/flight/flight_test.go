@@ -0,0 +1,126 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForWaiters blocks until exactly n callers wait for the call of key.
+func waitForWaiters(t *testing.T, g *Group[int], key string, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		g.mu.Lock()
+		waiters := 0
+		if c, ok := g.calls[key]; ok {
+			waiters = c.waiters
+		}
+		g.mu.Unlock()
+
+		if waiters == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d callers wait for %s, want %d", waiters, key, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDoCoalesces(t *testing.T) {
+	var g Group[int]
+	var calls, shared atomic.Int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			v, err, s := g.Do(context.Background(), "key", func(ctx context.Context) (int, error) {
+				calls.Add(1)
+				<-release
+				return 42, nil
+			})
+			if v != 42 || err != nil {
+				t.Errorf("Do = %d, %v, want 42, nil", v, err)
+			}
+			if s {
+				shared.Add(1)
+			}
+		}()
+	}
+
+	waitForWaiters(t, &g, "key", 10)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", calls.Load())
+	}
+	if shared.Load() != 9 {
+		t.Errorf("shared = %d, want 9", shared.Load())
+	}
+}
+
+func TestDoKeepsRunningForOtherWaiters(t *testing.T) {
+	var g Group[int]
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go g.Do(ctx, "key", func(ctx context.Context) (int, error) {
+		close(started)
+		select {
+		case <-release:
+			return 42, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	})
+	<-started
+
+	done := make(chan int)
+	go func() {
+		v, _, _ := g.Do(context.Background(), "key", nil)
+		done <- v
+	}()
+
+	waitForWaiters(t, &g, "key", 2)
+	cancel()
+	waitForWaiters(t, &g, "key", 1)
+	close(release)
+
+	if v := <-done; v != 42 {
+		t.Errorf("Do = %d, want 42", v)
+	}
+}
+
+func TestDoCancelsWithoutWaiters(t *testing.T) {
+	var g Group[int]
+	canceled := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err, _ := g.Do(ctx, "key", func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		close(canceled)
+		return 0, ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("call was not canceled")
+	}
+}
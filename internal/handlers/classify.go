@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vsrecorder/decktype-api/internal/apierror"
+	"github.com/vsrecorder/decktype-api/internal/decklist"
+	"github.com/vsrecorder/decktype-api/internal/engine"
+)
+
+// maxDeckListBytes bounds the size of a posted deck list, like the size
+// limit of the deckcards API responses.
+const maxDeckListBytes = 1 << 20
+
+// PostClassify classifies the deck list in the request body, in the shape
+// of the deckcards API response, under the environment. It answers like the
+// GET endpoints of the environment, so decks that were never published can
+// be classified too. Results are not cached, since there is no deck code to
+// key them by.
+func PostClassify(ctx *gin.Context) {
+	env := ctx.Param("env")
+
+	ruleSet, ok := ruleStore.RuleSets()[env]
+	if !ok {
+		apierror.Write(ctx, http.StatusNotFound, apierror.UnknownEnvironment, "Unknown environment: "+env)
+		return
+	}
+	setRuleSetHeaders(ctx, ruleSet)
+
+	deck, ok := readDeck(ctx)
+	if !ok {
+		return
+	}
+
+	writeResult(ctx, ruleSet.Classify(deck))
+}
+
+// TextClassification is the classification of a text deck list, along
+// with the cards read from it and the lines that could not be read.
+type TextClassification struct {
+	*decklist.List
+	Result *engine.Result `json:"result"`
+}
+
+// PostClassifyText classifies the text deck list in the request body, such
+// as a PTCG Live export, under the environment. Card names are resolved
+// through the alias table. The response is 200 even if no rule matched, so
+// the client can show the lines that could not be read.
+func PostClassifyText(ctx *gin.Context) {
+	env := ctx.Param("env")
+
+	ruleSet, ok := ruleStore.RuleSets()[env]
+	if !ok {
+		apierror.Write(ctx, http.StatusNotFound, apierror.UnknownEnvironment, "Unknown environment: "+env)
+		return
+	}
+	setRuleSetHeaders(ctx, ruleSet)
+
+	text, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxDeckListBytes))
+	if err != nil {
+		apierror.Write(ctx, http.StatusBadRequest, apierror.InvalidDeckList, "Invalid deck list: "+err.Error())
+		return
+	}
+
+	list := decklist.Parse(string(text), aliases)
+	if len(list.Entries) == 0 {
+		apierror.Write(ctx, http.StatusBadRequest, apierror.InvalidDeckList, "Invalid deck list: no cards found")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &TextClassification{
+		List:   list,
+		Result: ruleSet.Classify(list.Cards()),
+	})
+}
+
+// readDeck reads the deck list in the request body. If it is not a list of
+// cards with positive counts it writes a 400 response and returns false.
+func readDeck(ctx *gin.Context) ([]*engine.Card, bool) {
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxDeckListBytes)
+
+	var deck []*engine.Card
+	if err := json.NewDecoder(body).Decode(&deck); err != nil {
+		apierror.Write(ctx, http.StatusBadRequest, apierror.InvalidDeckList, "Invalid deck list: "+err.Error())
+		return nil, false
+	}
+
+	for _, card := range deck {
+		if card == nil || card.Name == "" || card.Count <= 0 {
+			apierror.Write(ctx, http.StatusBadRequest, apierror.InvalidDeckList, "Invalid deck list: every card needs a name and a positive count")
+			return nil, false
+		}
+	}
+
+	return deck, true
+}
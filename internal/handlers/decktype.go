@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vsrecorder/decktype-api/internal/apierror"
+	"github.com/vsrecorder/decktype-api/internal/engine"
+)
+
+// ResultWriter writes a classification in the response shape of an API
+// version.
+type ResultWriter func(ctx *gin.Context, result *engine.Result)
+
+// GetDeckType classifies the deck under the environment that was active on
+// the date given as ?date=YYYY-MM-DD, usually the day the deck was played,
+// or under the newest environment without a date or for a date before the
+// oldest environment.
+var GetDeckType = DeckTypeHandler(writeResult)
+
+// GetEnvironment classifies the deck under the environment named in the
+// path.
+var GetEnvironment = EnvironmentHandler(writeResult)
+
+// DeckTypeHandler returns a handler like GetDeckType that writes the
+// classification with write.
+func DeckTypeHandler(write ResultWriter) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		date := ctx.Query("date")
+
+		ruleSet, err := ruleStore.RuleSetAt(date)
+		if err != nil {
+			apierror.Write(ctx, http.StatusBadRequest, apierror.InvalidDate, "Invalid date: "+date)
+			return
+		}
+
+		classify(ctx, ruleSet, write)
+	}
+}
+
+// EnvironmentHandler returns a handler like GetEnvironment that writes the
+// classification with write.
+func EnvironmentHandler(write ResultWriter) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		env := ctx.Param("env")
+
+		ruleSet, ok := ruleStore.RuleSets()[env]
+		if !ok {
+			apierror.Write(ctx, http.StatusNotFound, apierror.UnknownEnvironment, "Unknown environment: "+env)
+			return
+		}
+
+		classify(ctx, ruleSet, write)
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vsrecorder/decktype-api/internal/apierror"
+	"github.com/vsrecorder/decktype-api/internal/engine"
+)
+
+// GetEnvironments lists the environments decks can be classified under,
+// oldest first.
+func GetEnvironments(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, ruleStore.Environments())
+}
+
+// GetVersion returns the version of the rules of an environment. The
+// version changes whenever the rules do, so results stored with an older
+// version may classify the deck differently today.
+func GetVersion(ctx *gin.Context) {
+	env := ctx.Param("env")
+
+	ruleSet, ok := ruleStore.RuleSets()[env]
+	if !ok {
+		apierror.Write(ctx, http.StatusNotFound, apierror.UnknownEnvironment, "Unknown environment: "+env)
+		return
+	}
+	setRuleSetHeaders(ctx, ruleSet)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"environment": ruleSet.Environment,
+		"version":     ruleSet.Version,
+	})
+}
+
+// GetAllEnvironments classifies the deck under every environment, taking
+// the results from the cache where possible and fetching the deck at most
+// once for the rest. The response maps each environment to its result;
+// primary is null in the result of an environment where no rule matched.
+//
+// While the deck source is failing, the environments that missed the cache
+// are answered from expired entries, marked with the stale header, and
+// those without one are left out. The error is reported only if no
+// environment can be answered.
+func GetAllEnvironments(ctx *gin.Context) {
+	deckCode := ctx.Param("id")
+	ruleSets := ruleStore.RuleSets()
+
+	if !apierror.CheckDeckCode(ctx, deckCode) {
+		return
+	}
+
+	results := make(map[string]*engine.Result, len(ruleSets))
+	var missing []*engine.RuleSet
+	for env, ruleSet := range ruleSets {
+		if result, ok := resultCache.Get(ruleSet, deckCode); ok {
+			results[env] = result
+		} else {
+			missing = append(missing, ruleSet)
+		}
+	}
+
+	if len(missing) > 0 {
+		deck, err, shared := fetches.Do(ctx.Request.Context(), deckCode, func(fctx context.Context) ([]*engine.Card, error) {
+			return deckSource.Fetch(fctx, deckCode)
+		})
+
+		coalescing.Add("requests", 1)
+		if shared {
+			coalescing.Add("coalesced", 1)
+		}
+
+		if err != nil {
+			if !upstreamDown(err) {
+				apierror.WriteFetch(ctx, err)
+				return
+			}
+
+			stale := false
+			for _, ruleSet := range missing {
+				if result, ok := resultCache.Stale(ruleSet, deckCode); ok {
+					results[ruleSet.Environment] = result
+					stale = true
+				}
+			}
+			if !stale {
+				apierror.WriteFetch(ctx, err)
+				return
+			}
+
+			ctx.Header(staleHeader, "true")
+			missing = nil
+		}
+
+		for _, ruleSet := range missing {
+			result := ruleSet.Classify(deck)
+			if result.Primary != nil {
+				resultCache.Add(ruleSet, deckCode, result)
+			}
+			results[ruleSet.Environment] = result
+		}
+	}
+
+	ctx.JSON(http.StatusOK, results)
+}
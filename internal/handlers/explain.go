@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vsrecorder/decktype-api/internal/apierror"
+)
+
+// GetExplanation reports, for every rule of the environment, whether the
+// deck matched it, how each part of its condition evaluated and which of
+// its main cards are in the deck.
+func GetExplanation(ctx *gin.Context) {
+	deckCode := ctx.Param("id")
+	env := ctx.Param("env")
+
+	ruleSet, ok := ruleStore.RuleSets()[env]
+	if !ok {
+		apierror.Write(ctx, http.StatusNotFound, apierror.UnknownEnvironment, "Unknown environment: "+env)
+		return
+	}
+	setRuleSetHeaders(ctx, ruleSet)
+
+	deck, ok := fetchDeck(ctx, deckCode)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ruleSet.Explain(deck))
+}
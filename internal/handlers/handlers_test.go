@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vsrecorder/decktype-api/internal/apierror"
+	"github.com/vsrecorder/decktype-api/internal/cache"
+	"github.com/vsrecorder/decktype-api/internal/decklist"
+	"github.com/vsrecorder/decktype-api/internal/decksource"
+	"github.com/vsrecorder/decktype-api/internal/engine"
+)
+
+const testRules = `environment: x
+name: x
+set: x
+regulation_marks: ["H"]
+start: 2026-01-01
+rules:
+  - title: "ドラパルトex"
+    when: count("ドラパルトex") >= 2
+    main_cards: ["ドラパルトex"]
+  - title: "イダイナキバ"
+    when: count("イダイナキバ") >= 3 && count("ニュートラルセンター(ACE SPEC)") == 1
+    main_cards: ["イダイナキバ", "ニュートラルセンター(ACE SPEC)"]
+`
+
+// otherRules is a second environment, in which the test decks match no rule.
+const otherRules = `environment: y
+name: y
+set: y
+regulation_marks: ["H"]
+start: 2026-02-01
+rules:
+  - title: "メガルカリオex"
+    when: count("メガルカリオex") >= 2
+    main_cards: ["メガルカリオex"]
+`
+
+const (
+	dragapult = "aaaaaa-bbbbbb-cccccc"
+	blank     = "dddddd-eeeeee-ffffff"
+)
+
+var dragapultDeck = []*engine.Card{
+	{Name: "ドラパルトex", Count: 3},
+	{Name: "ドロンチ", Count: 3},
+}
+
+// setup installs the test rules, a fresh cache and source, and returns a
+// router with the routes of main.
+func setup(t *testing.T, c *cache.Cache, source decksource.Source) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	fsys := fstest.MapFS{
+		"x.yaml":      &fstest.MapFile{Data: []byte(testRules)},
+		"y.yaml":      &fstest.MapFile{Data: []byte(otherRules)},
+		"aliases.txt": &fstest.MapFile{Data: []byte("Dragapult ex = ドラパルトex\nNeutral Center = ニュートラルセンター\n")},
+		"acespec.txt": &fstest.MapFile{Data: []byte("ニュートラルセンター\n")},
+	}
+	store, err := engine.NewStore(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliases, err := decklist.LoadAliases(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetStore(store)
+	SetCache(c)
+	SetSource(source)
+	SetAliases(aliases)
+
+	r := gin.New()
+	r.POST("/environments/:env/classify", PostClassify)
+	r.POST("/environments/:env/classify/text", PostClassifyText)
+	r.GET("/decktypes/:id", GetDeckType)
+	r.GET("/decktypes/:id/environments", GetAllEnvironments)
+	r.GET("/decktypes/:id/environments/:env", GetEnvironment)
+
+	return r
+}
+
+func serve(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return w
+}
+
+func TestPostClassify(t *testing.T) {
+	r := setup(t, cache.New(cache.DefaultConfig, nil), decksource.NewMemory(map[string][]*engine.Card{
+		dragapult: dragapultDeck,
+	}))
+
+	for _, test := range []struct {
+		name   string
+		env    string
+		body   string
+		status int
+		code   apierror.Code
+	}{
+		{"malformed", "x", `{"name":`, http.StatusBadRequest, apierror.InvalidDeckList},
+		{"not a list", "x", `{"name": "ドラパルトex"}`, http.StatusBadRequest, apierror.InvalidDeckList},
+		{"no count", "x", `[{"name": "ドラパルトex"}]`, http.StatusBadRequest, apierror.InvalidDeckList},
+		{"no name", "x", `[{"count": 2}]`, http.StatusBadRequest, apierror.InvalidDeckList},
+		{"null card", "x", `[null]`, http.StatusBadRequest, apierror.InvalidDeckList},
+		{"unknown environment", "z", `[]`, http.StatusNotFound, apierror.UnknownEnvironment},
+		{"no match", "x", `[{"name": "ドロンチ", "count": 3}]`, http.StatusNoContent, ""},
+		{"match", "x", `[{"name": "ドラパルトex", "count": 3}, {"name": "ドロンチ", "count": 3}]`, http.StatusOK, ""},
+	} {
+		w := serve(r, http.MethodPost, "/environments/"+test.env+"/classify", test.body)
+		if w.Code != test.status {
+			t.Errorf("%s: status %d, want %d: %s", test.name, w.Code, test.status, w.Body)
+			continue
+		}
+
+		if test.code != "" {
+			var body apierror.Error
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("%s: %s", test.name, err)
+			}
+			if body.Code != test.code {
+				t.Errorf("%s: code %s, want %s", test.name, body.Code, test.code)
+			}
+			continue
+		}
+
+		if got := w.Header().Get(environmentHeader); got != "x" {
+			t.Errorf("%s: %s = %q, want %q", test.name, environmentHeader, got, "x")
+		}
+	}
+
+	// The result of a posted deck list has the shape of the result of the
+	// same deck fetched by its deck code.
+	posted := serve(r, http.MethodPost, "/environments/x/classify", `[{"name": "ドラパルトex", "count": 3}, {"name": "ドロンチ", "count": 3}]`)
+	fetched := serve(r, http.MethodGet, "/decktypes/"+dragapult+"/environments/x", "")
+	if fetched.Code != http.StatusOK || posted.Body.String() != fetched.Body.String() {
+		t.Errorf("posted deck list:\n%s\nwant, like the fetched deck (%d):\n%s", posted.Body, fetched.Code, fetched.Body)
+	}
+}
+
+func TestPostClassifyText(t *testing.T) {
+	r := setup(t, cache.New(cache.DefaultConfig, nil), decksource.NewMemory(nil))
+
+	w := serve(r, http.MethodPost, "/environments/x/classify/text", "Pokémon: 7\n3 Dragapult ex TWM 130\n4 ドロンチ\nnot a card\n")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var got struct {
+		Cards  []*decklist.Entry     `json:"cards"`
+		Lines  []*decklist.LineError `json:"unparsed_lines"`
+		Result *engine.Result        `json:"result"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Cards) != 2 || got.Cards[0].Name != "ドラパルトex" || got.Cards[0].Set != "TWM" {
+		t.Errorf("cards = %+v", got.Cards)
+	}
+	if len(got.Lines) != 1 || got.Lines[0].Line != 4 {
+		t.Errorf("unparsed lines = %+v, want line 4", got.Lines)
+	}
+	if got.Result == nil || got.Result.Primary == nil || got.Result.Primary.Title != "ドラパルトex" {
+		t.Errorf("result = %+v, want ドラパルトex", got.Result)
+	}
+
+	// Text deck lists name ACE SPEC cards without the suffix the rules use.
+	for _, text := range []string{"4 イダイナキバ\n1 ニュートラルセンター\n", "4 イダイナキバ\n1 Neutral Center SFA 60\n"} {
+		w := serve(r, http.MethodPost, "/environments/x/classify/text", text)
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Result.Primary == nil || got.Result.Primary.Title != "イダイナキバ" || got.Result.AceSpec == nil {
+			t.Errorf("%q: result = %s, want イダイナキバ with an ACE SPEC card", text, w.Body)
+		}
+	}
+
+	// A list without a matching rule still reports its cards.
+	if w := serve(r, http.MethodPost, "/environments/x/classify/text", "4 ドロンチ\n"); w.Code != http.StatusOK {
+		t.Errorf("no match: status %d, want %d", w.Code, http.StatusOK)
+	}
+
+	for body, want := range map[string]int{
+		"":              http.StatusBadRequest,
+		"not a card\n":  http.StatusBadRequest,
+		"Pokémon: 12\n": http.StatusBadRequest,
+	} {
+		if w := serve(r, http.MethodPost, "/environments/x/classify/text", body); w.Code != want {
+			t.Errorf("%q: status %d, want %d", body, w.Code, want)
+		}
+	}
+	if w := serve(r, http.MethodPost, "/environments/z/classify/text", "4 ドロンチ\n"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown environment: status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+// failingSource fails every fetch like an upstream answering with 5xx.
+type failingSource struct{}
+
+func (failingSource) Fetch(ctx context.Context, deckCode string) ([]*engine.Card, error) {
+	return nil, &decksource.Error{Kind: decksource.KindUpstream, DeckCode: deckCode}
+}
+
+func TestClassifyStale(t *testing.T) {
+	c := cache.New(cache.Config{Size: 10, TTL: time.Millisecond}, nil)
+	r := setup(t, c, decksource.NewMemory(map[string][]*engine.Card{dragapult: dragapultDeck}))
+
+	path := "/decktypes/" + dragapult + "/environments/x"
+	fresh := serve(r, http.MethodGet, path, "")
+	if fresh.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d", fresh.Code, http.StatusOK)
+	}
+
+	breaker := decksource.NewBreaker(failingSource{}, 1, time.Hour)
+	breaker.Fetch(context.Background(), dragapult)
+	if !breaker.Open() {
+		t.Fatal("breaker is closed")
+	}
+	SetSource(breaker)
+	time.Sleep(5 * time.Millisecond)
+
+	w := serve(r, http.MethodGet, path, "")
+	if w.Code != http.StatusOK || w.Header().Get(staleHeader) != "true" {
+		t.Fatalf("status %d, %s = %q, want %d and true", w.Code, staleHeader, w.Header().Get(staleHeader), http.StatusOK)
+	}
+	if w.Body.String() != fresh.Body.String() {
+		t.Errorf("stale body:\n%s\nwant:\n%s", w.Body, fresh.Body)
+	}
+
+	// Without an expired entry the failure is reported.
+	w = serve(r, http.MethodGet, "/decktypes/"+blank+"/environments/x", "")
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get(staleHeader) != "" {
+		t.Errorf("uncached deck: status %d, %s = %q, want %d and no header", w.Code, staleHeader, w.Header().Get(staleHeader), http.StatusServiceUnavailable)
+	}
+}
+
+func TestAllEnvironmentsStale(t *testing.T) {
+	c := cache.New(cache.Config{Size: 10, TTL: time.Millisecond}, nil)
+	r := setup(t, c, decksource.NewMemory(map[string][]*engine.Card{dragapult: dragapultDeck}))
+
+	path := "/decktypes/" + dragapult + "/environments"
+	if w := serve(r, http.MethodGet, path, ""); w.Code != http.StatusOK || w.Header().Get(staleHeader) != "" {
+		t.Fatalf("status %d, %s = %q, want %d and no header", w.Code, staleHeader, w.Header().Get(staleHeader), http.StatusOK)
+	}
+
+	SetSource(decksource.NewBreaker(failingSource{}, 1, time.Hour))
+	time.Sleep(5 * time.Millisecond)
+
+	w := serve(r, http.MethodGet, path, "")
+	if w.Code != http.StatusOK || w.Header().Get(staleHeader) != "true" {
+		t.Fatalf("status %d, %s = %q, want %d and true", w.Code, staleHeader, w.Header().Get(staleHeader), http.StatusOK)
+	}
+
+	// Only x matched, so only x has an expired result to serve.
+	var results map[string]*engine.Result
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results["x"] == nil || results["x"].Primary.Title != "ドラパルトex" {
+		t.Errorf("results = %s, want only x", w.Body)
+	}
+
+	w = serve(r, http.MethodGet, "/decktypes/"+blank+"/environments", "")
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get(staleHeader) != "" {
+		t.Errorf("uncached deck: status %d, %s = %q, want %d and no header", w.Code, staleHeader, w.Header().Get(staleHeader), http.StatusServiceUnavailable)
+	}
+}
+
+func TestDeckTypeDate(t *testing.T) {
+	r := setup(t, cache.New(cache.DefaultConfig, nil), decksource.NewMemory(map[string][]*engine.Card{dragapult: dragapultDeck}))
+
+	for date, want := range map[string]struct {
+		status int
+		env    string
+	}{
+		"":           {http.StatusNoContent, "y"},
+		"2026-01-15": {http.StatusOK, "x"},
+		"2026-03-01": {http.StatusNoContent, "y"},
+		// Before the oldest environment the newest one is used too.
+		"2025-12-31": {http.StatusNoContent, "y"},
+		"2026-1-15":  {http.StatusBadRequest, ""},
+	} {
+		w := serve(r, http.MethodGet, "/decktypes/"+dragapult+"?date="+date, "")
+		if w.Code != want.status || w.Header().Get(environmentHeader) != want.env {
+			t.Errorf("date %q: status %d, %s = %q, want %d and %q",
+				date, w.Code, environmentHeader, w.Header().Get(environmentHeader), want.status, want.env)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vsrecorder/decktype-api/internal/apierror"
+)
+
+const (
+	defaultMaxDistance = 3
+	defaultLimit       = 5
+)
+
+// GetNearMisses lists the rules of the environment the deck did not match
+// but came closest to, with the parts of their conditions that failed.
+func GetNearMisses(ctx *gin.Context) {
+	deckCode := ctx.Param("id")
+	env := ctx.Param("env")
+
+	ruleSet, ok := ruleStore.RuleSets()[env]
+	if !ok {
+		apierror.Write(ctx, http.StatusNotFound, apierror.UnknownEnvironment, "Unknown environment: "+env)
+		return
+	}
+	setRuleSetHeaders(ctx, ruleSet)
+
+	maxDistance, ok := queryInt(ctx, "max_distance", defaultMaxDistance)
+	if !ok {
+		return
+	}
+
+	limit, ok := queryInt(ctx, "limit", defaultLimit)
+	if !ok {
+		return
+	}
+
+	deck, ok := fetchDeck(ctx, deckCode)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ruleSet.NearMisses(deck, maxDistance, limit))
+}
+
+// queryInt reads a non-negative integer query parameter, writing a 400
+// response and returning false if it is malformed.
+func queryInt(ctx *gin.Context, key string, def int) (int, bool) {
+	s, ok := ctx.GetQuery(key)
+	if !ok {
+		return def, true
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		apierror.Write(ctx, http.StatusBadRequest, apierror.InvalidParameter, "Invalid "+key+": "+s)
+		return 0, false
+	}
+
+	return n, true
+}
@@ -1,48 +1,170 @@
 package handlers
 
-import lru "github.com/hashicorp/golang-lru/v2"
+import (
+	"context"
+	"expvar"
+	"net/http"
 
-var cache, _ = lru.New[string, []*DeckType](2000)
+	"github.com/gin-gonic/gin"
+	"github.com/vsrecorder/decktype-api/internal/apierror"
+	"github.com/vsrecorder/decktype-api/internal/cache"
+	"github.com/vsrecorder/decktype-api/internal/decklist"
+	"github.com/vsrecorder/decktype-api/internal/decksource"
+	"github.com/vsrecorder/decktype-api/internal/engine"
+	"github.com/vsrecorder/decktype-api/internal/flight"
+)
 
-type Card struct {
-	Name      string `json:"name"`
-	DetailURL string `json:"detail_url"`
-	ImageURL  string `json:"image_url"`
-	Count     int    `json:"count"`
+const (
+	// environmentHeader carries the environment a response was computed
+	// under, which a 204 has no body to report.
+	environmentHeader = "X-Environment"
+	// versionHeader carries the version of the rule set behind a response.
+	versionHeader = "X-Rule-Set-Version"
+)
+
+// classifications coalesces concurrent classifications of the same deck
+// under the same rules.
+var classifications flight.Group[*engine.Result]
+
+// fetches coalesces concurrent fetches of the same deck by
+// GetAllEnvironments.
+var fetches flight.Group[[]*engine.Card]
+
+// coalescing counts the classifications that missed the cache as requests,
+// and those of them that waited for another request's classification as
+// coalesced. It is served by GetVars.
+var coalescing = expvar.NewMap("coalescing")
+
+// staleHeader marks a response served from an expired cache entry because
+// the deck could not be fetched.
+const staleHeader = "X-Stale"
+
+var resultCache = cache.New(cache.DefaultConfig, nil)
+
+var ruleStore *engine.Store
+
+var deckSource decksource.Source = decksource.NewHTTP(decksource.DefaultConfig)
+
+var aliases = decklist.Aliases{}
+
+// SetStore installs the store the environment handlers take their rule sets
+// from.
+func SetStore(store *engine.Store) {
+	ruleStore = store
 }
 
-type MainCard struct {
-	Name     string `json:"name"`
-	ImageURL string `json:"image_url"`
+// SetSource installs the source deck lists are fetched from.
+func SetSource(source decksource.Source) {
+	deckSource = source
 }
 
-type DeckType struct {
-	Title     string      `json:"title"`
-	MainCards []*MainCard `json:"main_cards"`
+// SetCache installs the cache of classification results.
+func SetCache(c *cache.Cache) {
+	resultCache = c
 }
 
-func analyze(title string, deck []*Card, cards []string) *DeckType {
-	var mainCards []*MainCard
+// SetAliases installs the alias table names of text deck lists are resolved
+// through.
+func SetAliases(a decklist.Aliases) {
+	aliases = a
+}
+
+// setRuleSetHeaders reports the environment and version of the rule set
+// behind the response in its headers.
+func setRuleSetHeaders(ctx *gin.Context, ruleSet *engine.RuleSet) {
+	ctx.Header(environmentHeader, ruleSet.Environment)
+	ctx.Header(versionHeader, ruleSet.Version)
+}
+
+// classify writes the classification of the deck under the rule set with
+// write, taking it from the cache when possible. Concurrent requests for the
+// same deck under the same rules share one fetch and classification.
+func classify(ctx *gin.Context, ruleSet *engine.RuleSet, write ResultWriter) {
+	deckCode := ctx.Param("id")
+	setRuleSetHeaders(ctx, ruleSet)
+
+	if !apierror.CheckDeckCode(ctx, deckCode) {
+		return
+	}
+
+	ret, ok := resultCache.Get(ruleSet, deckCode)
+	if ok {
+		write(ctx, ret)
+		return
+	}
+
+	key := ruleSet.Environment + "/" + ruleSet.Version + "/" + deckCode
+	result, err, shared := classifications.Do(ctx.Request.Context(), key, func(fctx context.Context) (*engine.Result, error) {
+		deck, err := deckSource.Fetch(fctx, deckCode)
+		if err != nil {
+			return nil, err
+		}
 
-	for _, cardname := range cards {
-		for _, card := range deck {
-			if card.Name == cardname {
-				mainCards = append(
-					mainCards,
-					&MainCard{
-						Name:     card.Name,
-						ImageURL: card.ImageURL,
-					},
-				)
-				break
-			}
+		result := ruleSet.Classify(deck)
+		if result.Primary != nil {
+			resultCache.Add(ruleSet, deckCode, result)
 		}
+
+		return result, nil
+	})
+
+	coalescing.Add("requests", 1)
+	if shared {
+		coalescing.Add("coalesced", 1)
+	}
+
+	if err != nil {
+		stale, ok := resultCache.Stale(ruleSet, deckCode)
+		if !ok || !upstreamDown(err) {
+			apierror.WriteFetch(ctx, err)
+			return
+		}
+
+		ctx.Header(staleHeader, "true")
+		result = stale
+	}
+
+	write(ctx, result)
+}
+
+// writeResult writes a classification: 204 if no rule matched, since the
+// client has nothing to show, and 200 otherwise.
+func writeResult(ctx *gin.Context, result *engine.Result) {
+	if result.Primary == nil {
+		ctx.JSON(http.StatusNoContent, result)
+	} else {
+		ctx.JSON(http.StatusOK, result)
 	}
+}
+
+// GetVars serves the counters of the service in the format of expvar. Only
+// the counters of this package are served; the command line and memory
+// statistics that expvar publishes as well are kept private.
+func GetVars(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "application/json; charset=utf-8", []byte(`{"coalescing": `+coalescing.String()+"}"))
+}
 
-	deckType := &DeckType{
-		Title:     title,
-		MainCards: mainCards,
+// fetchDeck fetches the deck list of deckCode from the deck source. If the
+// deck code is malformed or the fetch fails it writes the error response and
+// returns false.
+func fetchDeck(ctx *gin.Context, deckCode string) ([]*engine.Card, bool) {
+	if !apierror.CheckDeckCode(ctx, deckCode) {
+		return nil, false
 	}
 
-	return deckType
+	deck, err := deckSource.Fetch(ctx.Request.Context(), deckCode)
+	if err != nil {
+		apierror.WriteFetch(ctx, err)
+		return nil, false
+	}
+
+	return deck, true
+}
+
+// upstreamDown reports whether err means the deck source is failing, as
+// opposed to the deck not existing.
+func upstreamDown(err error) bool {
+	return decksource.IsKind(err, decksource.KindUnavailable) ||
+		decksource.IsKind(err, decksource.KindUpstream) ||
+		decksource.IsKind(err, decksource.KindTimeout)
 }
@@ -2,19 +2,57 @@ package main
 
 import (
 	"context"
+	"io/fs"
 	"log"
+	"maps"
 	"net/http"
+	"os"
 	"os/signal"
+	"slices"
 	"syscall"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/vsrecorder/decktype-api/internal/beta"
+	"github.com/vsrecorder/decktype-api/internal/cache"
+	"github.com/vsrecorder/decktype-api/internal/decklist"
+	"github.com/vsrecorder/decktype-api/internal/decksource"
+	"github.com/vsrecorder/decktype-api/internal/engine"
 	"github.com/vsrecorder/decktype-api/internal/handlers"
+	"github.com/vsrecorder/decktype-api/rules"
 )
 
 func main() {
+	var rulesFS fs.FS = rules.FS
+	if dir := os.Getenv("DECKTYPE_RULES_DIR"); dir != "" {
+		rulesFS = os.DirFS(dir)
+	}
+
+	ruleStore, err := engine.NewStore(rulesFS)
+	if err != nil {
+		log.Fatalf("failed to load rules: %s\n", err)
+	}
+	handlers.SetStore(ruleStore)
+
+	aliases, err := decklist.LoadAliases(rulesFS)
+	if err != nil {
+		log.Fatalf("failed to load aliases: %s\n", err)
+	}
+	handlers.SetAliases(aliases)
+
+	defaultCache, cacheConfigs, err := cache.ConfigFromEnv(slices.Collect(maps.Keys(ruleStore.RuleSets())))
+	if err != nil {
+		log.Fatalf("failed to configure the cache: %s\n", err)
+	}
+	handlers.SetCache(cache.New(defaultCache, cacheConfigs))
+
+	deckSource, err := decksource.FromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure the deck source: %s\n", err)
+	}
+	handlers.SetSource(deckSource)
+
 	r := gin.Default()
 	r.SetTrustedProxies(nil)
 	r.Use(cors.New(cors.Config{
@@ -26,8 +64,14 @@ func main() {
 		},
 		AllowMethods: []string{
 			"GET",
+			"POST",
 			"OPTIONS",
 		},
+		ExposeHeaders: []string{
+			"X-Environment",
+			"X-Rule-Set-Version",
+			"X-Stale",
+		},
 		AllowOrigins: []string{
 			"http://localhost:3000",
 			"https://local.vsrecorder.mobi",
@@ -38,47 +82,82 @@ func main() {
 	}))
 
 	r.GET(
-		"/decktypes/:id",
-		handlers.GetM4,
+		"/debug/vars",
+		handlers.GetVars,
+	)
+
+	r.GET(
+		"/environments",
+		handlers.GetEnvironments,
 	)
 
 	r.GET(
-		"/decktypes/:id/environments/m4",
-		handlers.GetM4,
+		"/environments/:env/version",
+		handlers.GetVersion,
+	)
+
+	r.POST(
+		"/environments/:env/classify",
+		handlers.PostClassify,
 	)
+
+	r.POST(
+		"/environments/:env/classify/text",
+		handlers.PostClassifyText,
+	)
+
 	r.GET(
-		"/decktypes/:id/environments/m3",
-		handlers.GetM3,
+		"/decktypes/:id",
+		handlers.GetDeckType,
 	)
 
 	r.GET(
-		"/decktypes/:id/environments/mc",
-		handlers.GetMc,
+		"/decktypes/:id/environments",
+		handlers.GetAllEnvironments,
 	)
 
 	r.GET(
-		"/decktypes/:id/environments/m2a",
-		handlers.GetM2a,
+		"/decktypes/:id/environments/:env",
+		handlers.GetEnvironment,
 	)
 
 	r.GET(
-		"/decktypes/:id/environments/m2",
-		handlers.GetM2,
+		"/decktypes/:id/environments/:env/explain",
+		handlers.GetExplanation,
 	)
 
 	r.GET(
-		"/decktypes/:id/environments/m1",
-		handlers.GetM1,
+		"/decktypes/:id/environments/:env/near-misses",
+		handlers.GetNearMisses,
 	)
 
 	r.GET(
 		"/api/v1beta/decktypes/:id",
-		beta.GetM2a,
+		beta.GetDeckType,
+	)
+
+	r.GET(
+		"/api/v1beta/decktypes/:id/environments/:env",
+		beta.GetEnvironment,
 	)
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	// Reload the rules on SIGHUP and whenever a rule file changes
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if err := ruleStore.Reload(); err != nil {
+				log.Printf("failed to reload rules, keeping the previous rules: %s\n", err)
+				continue
+			}
+			log.Println("rules reloaded")
+		}
+	}()
+	go ruleStore.Watch(ctx, 5*time.Second)
+
 	srv := &http.Server{
 		Addr:    ":8930",
 		Handler: r,
@@ -0,0 +1,228 @@
+package rules_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/vsrecorder/decktype-api/internal/engine"
+	"github.com/vsrecorder/decktype-api/rules"
+	"gopkg.in/yaml.v3"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/expected.yaml with the current classifications")
+
+const expectedFile = "testdata/expected.yaml"
+
+// golden is a deck of the regression corpus and the deck types it is
+// expected to get in every environment, primary first.
+type golden struct {
+	Deck        string                 `yaml:"deck"`
+	Description string                 `yaml:"description"`
+	Expected    map[string][]*deckType `yaml:"expected"`
+}
+
+// deckType is the title of an expected deck type and its main cards that are
+// in the deck.
+type deckType struct {
+	Title     string   `yaml:"title"`
+	MainCards []string `yaml:"main_cards"`
+}
+
+// MarshalYAML writes the deck type in flow style to keep expected.yaml
+// compact.
+func (d *deckType) MarshalYAML() (any, error) {
+	type plain deckType
+
+	var node yaml.Node
+	if err := node.Encode((*plain)(d)); err != nil {
+		return nil, err
+	}
+	node.Style = yaml.FlowStyle
+
+	return &node, nil
+}
+
+// TestGolden classifies every deck in testdata/decks, stored in the shape
+// of the deckcards API response, under every environment and compares the
+// titles and main cards with testdata/expected.yaml. Run with -update after an intended
+// change to accept the new classifications.
+func TestGolden(t *testing.T) {
+	ruleSets, err := engine.Load(rules.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envs := slices.Sorted(maps.Keys(ruleSets))
+
+	goldens, err := readGoldens()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := filepath.Glob("testdata/decks/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		code := strings.TrimSuffix(filepath.Base(path), ".json")
+		if slices.ContainsFunc(goldens, func(g *golden) bool { return g.Deck == code }) {
+			continue
+		}
+		if !*update {
+			t.Errorf("%s: deck has no expected deck types, run with -update to add them", code)
+			continue
+		}
+		goldens = append(goldens, &golden{Deck: code})
+	}
+
+	for _, g := range goldens {
+		t.Run(g.Deck, func(t *testing.T) {
+			deck, err := readDeck(g.Deck)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for env := range g.Expected {
+				if _, ok := ruleSets[env]; !ok {
+					t.Errorf("expected deck types for unknown environment %s", env)
+				}
+			}
+
+			if *update {
+				g.Expected = make(map[string][]*deckType)
+			}
+
+			for _, env := range envs {
+				got := deckTypes(ruleSets[env].Classify(deck))
+
+				if *update {
+					g.Expected[env] = got
+					continue
+				}
+
+				want, ok := g.Expected[env]
+				if !ok {
+					t.Errorf("%s: no expected deck types, run with -update to add them", env)
+					continue
+				}
+
+				if !slices.EqualFunc(want, got, equal) {
+					t.Errorf("%s (%s): classification changed\n%s", env, g.Description, diff(want, got))
+				}
+			}
+		})
+	}
+
+	if *update {
+		if err := writeGoldens(goldens); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readGoldens() ([]*golden, error) {
+	data, err := os.ReadFile(expectedFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var goldens []*golden
+	if err := yaml.Unmarshal(data, &goldens); err != nil {
+		return nil, fmt.Errorf("%s: %w", expectedFile, err)
+	}
+
+	return goldens, nil
+}
+
+func writeGoldens(goldens []*golden) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(goldens); err != nil {
+		return err
+	}
+
+	return os.WriteFile(expectedFile, buf.Bytes(), 0o644)
+}
+
+func readDeck(code string) ([]*engine.Card, error) {
+	data, err := os.ReadFile(filepath.Join("testdata/decks", code+".json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var deck []*engine.Card
+	if err := json.Unmarshal(data, &deck); err != nil {
+		return nil, fmt.Errorf("%s: %w", code, err)
+	}
+
+	return deck, nil
+}
+
+func deckTypes(result *engine.Result) []*deckType {
+	deckTypes := []*deckType{}
+	for _, d := range append([]*engine.DeckType{result.Primary}, result.Secondaries...) {
+		if d == nil {
+			continue
+		}
+
+		mainCards := []string{}
+		for _, card := range d.MainCards {
+			mainCards = append(mainCards, card.Name)
+		}
+		deckTypes = append(deckTypes, &deckType{Title: d.Title, MainCards: mainCards})
+	}
+
+	return deckTypes
+}
+
+func equal(a, b *deckType) bool {
+	return a.Title == b.Title && slices.Equal(a.MainCards, b.MainCards)
+}
+
+// diff describes how got differs from want: titles that are no longer
+// returned, titles that are new, main cards that changed, and the order when
+// only that changed.
+func diff(want, got []*deckType) string {
+	var sb strings.Builder
+	for _, w := range want {
+		i := slices.IndexFunc(got, func(g *deckType) bool { return g.Title == w.Title })
+		if i < 0 {
+			fmt.Fprintf(&sb, "    - %s\n", w.Title)
+		} else if !slices.Equal(w.MainCards, got[i].MainCards) {
+			fmt.Fprintf(&sb, "    ~ %s: main cards %s, want %s\n", w.Title, join(got[i].MainCards), join(w.MainCards))
+		}
+	}
+	for _, g := range got {
+		if !slices.ContainsFunc(want, func(w *deckType) bool { return w.Title == g.Title }) {
+			fmt.Fprintf(&sb, "    + %s\n", g.Title)
+		}
+	}
+	fmt.Fprintf(&sb, "    want: %s\n", join(titles(want)))
+	fmt.Fprintf(&sb, "    got:  %s", join(titles(got)))
+
+	return sb.String()
+}
+
+func titles(deckTypes []*deckType) []string {
+	titles := make([]string, len(deckTypes))
+	for i, d := range deckTypes {
+		titles[i] = d.Title
+	}
+
+	return titles
+}
+
+func join(names []string) string {
+	if len(names) == 0 {
+		return "(none)"
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,10 @@
+// Package rules embeds the archetype rule files of every environment.
+// Each <environment>.yaml file lists the rules in evaluation order,
+// acespec.txt lists the ACE SPEC cards and aliases.txt maps the names of
+// text deck lists to the names the rules use.
+package rules
+
+import "embed"
+
+//go:embed *.yaml acespec.txt aliases.txt
+var FS embed.FS